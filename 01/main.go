@@ -1,12 +1,13 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"sort"
-	"strconv"
-	"strings"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 1: Challenge
@@ -18,42 +19,41 @@ const (
 )
 
 func main() {
+	run := result.Main(1, filename)
+
 	// Read input file and parse it into two lists of integers
 	list0, list1, err := readInput(filename)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		run.Fatal(err)
 	}
 
 	// Part 1
 	// Calculate the sum of absolute differences between sorted lists
-	fmt.Println("part 1")
-
-	// Create sorted copies of the original lists
-	sortedL0 := append([]int{}, list0...)
-	sortedL1 := append([]int{}, list1...)
-	sort.Ints(sortedL0) // Sort the first list
-	sort.Ints(sortedL1) // Sort the second list
-
-	// Calculate and print the result for Part 1
-	fmt.Println(partOneBruteForceSolution(sortedL0, sortedL1))
+	run.Part(1, func() (any, error) {
+		// Create sorted copies of the original lists
+		sortedL0 := append([]int{}, list0...)
+		sortedL1 := append([]int{}, list1...)
+		sort.Ints(sortedL0) // Sort the first list
+		sort.Ints(sortedL1) // Sort the second list
+		return partOneBruteForceSolution(sortedL0, sortedL1), nil
+	})
 
 	// Part 2
 	// Calculate the similarity score
-	fmt.Println("part 2")
-	// Create a map from list0 with all keys initialized to 0
-	m0 := listToZeroMap(list0)
-	// Update the map with the cardinality (frequency) of elements in list1
-	m := populateCardinalityFromList(m0, list1)
-	// Calculate and print the similarity score based on list0 and the map
-	simScore := calcSimScore(m, list0)
-	fmt.Println(simScore)
+	run.Part(2, func() (any, error) {
+		// Create a map from list0 with all keys initialized to 0
+		m0 := listToZeroMap(list0)
+		// Update the map with the cardinality (frequency) of elements in list1
+		m := populateCardinalityFromList(m0, list1)
+		// Calculate the similarity score based on list0 and the map
+		return calcSimScore(m, list0), nil
+	})
+
+	run.Finish()
 }
 
-// Reads the input file and parses it into two lists of integers
+// Reads the input file into the two lists of location IDs
 func readInput(fname string) ([]int, []int, error) {
-	var l0, l1 []int // Lists to store the parsed integers
-
 	// Open the input file
 	f, err := os.Open(fname)
 	if err != nil {
@@ -61,41 +61,45 @@ func readInput(fname string) ([]int, []int, error) {
 	}
 	defer f.Close() // Ensure the file is closed after the function completes
 
-	scanner := bufio.NewScanner(f)
-	var l []byte
-	for scanner.Scan() {
-		l = scanner.Bytes()                  // Read the line as a byte slice
-		v := strings.Split(string(l), delim) // Split the line using the delimiter
-		// Parse the first value as an integer
-		v0, err := strconv.Atoi(v[0])
-		if err != nil {
-			return nil, nil, fmt.Errorf("error parsing file [%s]: expected int for first value", fname)
-		}
-		// Parse the second value as an integer
-		v1, err := strconv.Atoi(v[1])
-		if err != nil {
-			return nil, nil, fmt.Errorf("error parsing file [%s]: expected int for second value", fname)
-		}
-		// Ensure the line contains exactly two values
-		if len(v) != 2 {
-			return nil, nil, fmt.Errorf("error parsing file [%s]: unexpected format", fname)
-		}
+	l0, l1, err := parseLists(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
+	}
+	return l0, l1, nil
+}
 
-		// Append the parsed values to the respective lists
-		l0 = append(l0, v0)
-		l1 = append(l1, v1)
+// Parses lines of two integers separated by the delimiter into two lists
+func parseLists(r io.Reader) ([]int, []int, error) {
+	pairs := input.NewSection("locations", parsePair)
+	if err := input.ReadSections(r, pairs); err != nil {
+		return nil, nil, err
 	}
 
-	// Check for any errors encountered during scanning
-	if err := scanner.Err(); err != nil {
-		return nil, nil, fmt.Errorf("error reading file [%s]: %w", fname, err)
+	l0 := make([]int, len(pairs.Values))
+	l1 := make([]int, len(pairs.Values))
+	for i, pair := range pairs.Values {
+		l0[i], l1[i] = pair[0], pair[1]
 	}
-	// Ensure the lists have the same length
-	if len(l0) != len(l1) {
-		return nil, nil, fmt.Errorf("unexpected format, lists are expected to be identical lengths")
+	return l0, l1, nil
+}
+
+// Parses a line of two integers (e.g., "3   4")
+func parsePair(line string) ([2]int, error) {
+	// Ensure the line contains exactly two values before reading them
+	v := input.Split(line, delim)
+	if len(v) != 2 {
+		return [2]int{}, fmt.Errorf("expected two integers separated by %q, found %d values", delim, len(v))
 	}
 
-	return l0, l1, nil
+	var pair [2]int
+	for i, field := range v {
+		n, err := field.Int()
+		if err != nil {
+			return [2]int{}, err
+		}
+		pair[i] = n
+	}
+	return pair, nil
 }
 
 ////////////
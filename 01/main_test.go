@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func FuzzParseLists(f *testing.F) {
+	f.Add("3   4\n4   3\n2   5\n1   3\n3   9\n3   3\n")
+	f.Add("3   4\n4\n")
+	f.Add("3   4   5\n")
+	f.Add("x   4\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		l0, l1, err := parseLists(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		if len(l0) != len(l1) {
+			t.Fatalf("lists of different lengths: %d and %d", len(l0), len(l1))
+		}
+	})
+}
@@ -1,11 +1,12 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
-	"strings"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 2: Challenge
@@ -18,22 +19,24 @@ const (
 )
 
 func main() {
+	run := result.Main(2, filename)
+
 	// Read the input file and parse it into a slice of integer slices
 	reports, err := readInput(filename)
 	if err != nil {
-		fmt.Println("error reading input file: %w", err) // Print error if file reading fails
-		os.Exit(1)
+		run.Fatal(err)
 	}
 
-	// Calculate the count of "safe" reports
-	safeCount := countSafe(reports)
-	fmt.Println(safeCount) // Print the number of safe reports
+	// Part 1: Count the "safe" reports
+	run.Part(1, func() (any, error) {
+		return countSafe(reports), nil
+	})
+
+	run.Finish()
 }
 
-// Reads the input file and parses each report
+// Reads the input file into reports, one per line
 func readInput(fname string) ([][]int, error) {
-	var reports [][]int // Slice to hold all parsed reports
-
 	// Open the input file
 	f, err := os.Open(fname)
 	if err != nil {
@@ -41,32 +44,33 @@ func readInput(fname string) ([][]int, error) {
 	}
 	defer f.Close() // Ensure the file is closed after the function completes
 
-	scanner := bufio.NewScanner(f) // Create a scanner to read the file line by line
-	for scanner.Scan() {
-		// Read the line and split it into strings based on the delimiter
-		line := scanner.Text()
-		values := strings.Split(line, delim)
-
-		// Parse the string values into integers
-		var report []int
-		for _, val := range values {
-			num, err := strconv.Atoi(val)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing file [%s]: expected int, found %s", fname, val)
-			}
-			report = append(report, num) // Append the parsed integer to the report
-		}
-
-		// Append the parsed report to the list of reports
-		reports = append(reports, report)
+	reports, err := parseReports(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
 	}
+	return reports, nil
+}
 
-	// Check for any errors encountered during scanning
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file [%s]: %w", fname, err)
+// Parses lines of integers separated by the delimiter into reports
+func parseReports(r io.Reader) ([][]int, error) {
+	reports := input.NewSection("reports", parseReport)
+	if err := input.ReadSections(r, reports); err != nil {
+		return nil, err
 	}
+	return reports.Values, nil
+}
 
-	return reports, nil // Return the list of parsed reports
+// Parses a report (e.g., "7 6 4 2 1")
+func parseReport(line string) ([]int, error) {
+	var report []int
+	for _, field := range input.Split(line, delim) {
+		num, err := field.Int()
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, num) // Append the parsed integer to the report
+	}
+	return report, nil
 }
 
 // Counts how many reports are "safe"
@@ -86,6 +90,11 @@ func countSafe(reports [][]int) int {
 
 // Determines whether a given report is "safe"
 func isSafe(report []int) bool {
+	// With fewer than two levels there is nothing to compare
+	if len(report) < 2 {
+		return true
+	}
+
 	// Track if the sequence is decreasing
 	var isDecreasing bool
 	// Calculate the difference between the first two numbers
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func FuzzParseReports(f *testing.F) {
+	f.Add("7 6 4 2 1\n1 2 7 8 9\n9 7 6 2 1\n1 3 2 4 5\n8 6 4 4 1\n1 3 6 7 9\n")
+	f.Add("5\n")
+	f.Add("1  2\n")
+	f.Add("1 2\n\n3 4\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		reports, err := parseReports(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		if n := countSafe(reports); n < 0 || n > len(reports) {
+			t.Fatalf("%d safe reports out of %d", n, len(reports))
+		}
+	})
+}
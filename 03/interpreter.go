@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// instruction is an entry in the instruction table: its syntax and what it does when executed
+type instruction struct {
+	arity int                          // Number of arguments the instruction takes
+	exec  func(m *machine, args []int) // Effect of the instruction on the machine
+}
+
+// instructionSet is every instruction the lexer recognizes
+// New instructions only need an entry here to be tokenized; machines opt in to executing them
+var instructionSet = map[string]instruction{
+	mulInstr:  {arity: 2, exec: execMul},
+	doInstr:   {arity: 0, exec: execDo},
+	dontInstr: {arity: 0, exec: execDont},
+}
+
+// machine is an interpreter configuration: the instructions it executes and its running state
+type machine struct {
+	table   map[string]instruction // Instructions this machine executes, others are ignored
+	enabled bool                   // Whether mul instructions currently take effect
+	sum     int                    // Running sum of the products of enabled mul instructions
+}
+
+// newMachine creates a machine that executes the named instructions from the instruction set
+func newMachine(names ...string) *machine {
+	m := &machine{
+		table:   make(map[string]instruction),
+		enabled: true,
+	}
+	for _, name := range names {
+		m.table[name] = instructionSet[name]
+	}
+	return m
+}
+
+// exec runs a single token, ignoring instructions the machine doesn't know
+func (m *machine) exec(t token) {
+	instr, ok := m.table[t.name]
+	if !ok {
+		return
+	}
+	instr.exec(m, t.args)
+}
+
+// run feeds every token from the lexer through each of the machines
+// If trace is non-nil it is called with each token after every machine has executed it
+func run(lx *lexer, trace func(token) error, machines ...*machine) error {
+	for {
+		t, err := lx.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, m := range machines {
+			m.exec(t)
+		}
+		if trace != nil {
+			if err := trace(t); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// execMul adds the product of its arguments to the sum if the machine is enabled
+func execMul(m *machine, args []int) {
+	if m.enabled {
+		m.sum += args[0] * args[1]
+	}
+}
+
+// execDo enables future mul instructions
+func execDo(m *machine, _ []int) {
+	m.enabled = true
+}
+
+// execDont disables future mul instructions
+func execDont(m *machine, _ []int) {
+	m.enabled = false
+}
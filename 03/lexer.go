@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+// Limits on instruction arguments: 1-3 digit numbers separated by commas
+const (
+	maxArgDigits int  = 3
+	argOpen      byte = '('
+	argClose     byte = ')'
+	argSep       byte = ','
+)
+
+// token is a single recognized instruction in the corrupted memory
+type token struct {
+	name   string // Instruction name, e.g. "mul"
+	args   []int  // Parsed arguments
+	offset int64  // Byte offset of the first byte of the instruction in the input
+	text   string // Raw instruction text, e.g. "mul(2,4)"
+}
+
+// lexer streams instruction tokens out of corrupted memory
+//
+// At every byte offset it tries to match one of the known instructions; if
+// none match it skips a single byte and tries again. This gives the same
+// leftmost, non-overlapping matches as scanning with a regex, while only
+// ever holding one instruction's worth of lookahead in memory.
+type lexer struct {
+	r      *bufio.Reader
+	specs  []instructionSpec // Known instructions, longest name first
+	first  [256]bool         // Bytes that can start an instruction
+	maxLen int               // Length of the longest possible instruction
+	offset int64             // Byte offset of the next unread byte
+	line   int               // 1-based line of the next unread byte, for errors
+	column int               // 1-based column of the next unread byte, for errors
+	onSkip func(b byte)      // Optional, called with each byte that isn't part of an instruction
+}
+
+// instructionSpec describes the syntax of an instruction: its name and how many arguments it takes
+type instructionSpec struct {
+	name  string
+	arity int
+}
+
+// newLexer creates a lexer recognizing the instructions in the given table
+func newLexer(r io.Reader, table map[string]instruction) *lexer {
+	lx := &lexer{r: bufio.NewReader(r), line: 1, column: 1}
+
+	for name, instr := range table {
+		spec := instructionSpec{name: name, arity: instr.arity}
+		lx.specs = append(lx.specs, spec)
+		lx.first[name[0]] = true
+		lx.maxLen = max(lx.maxLen, specMaxLen(spec))
+	}
+
+	// Try longer names first so the result doesn't depend on map iteration order
+	sort.Slice(lx.specs, func(i, j int) bool {
+		if len(lx.specs[i].name) != len(lx.specs[j].name) {
+			return len(lx.specs[i].name) > len(lx.specs[j].name)
+		}
+		return lx.specs[i].name < lx.specs[j].name
+	})
+	return lx
+}
+
+// specMaxLen returns the length of the longest valid spelling of an instruction, e.g. 12 for mul(123,456)
+func specMaxLen(spec instructionSpec) int {
+	n := len(spec.name) + 2 // name + parentheses
+	if spec.arity > 0 {
+		n += spec.arity*maxArgDigits + spec.arity - 1 // digits + commas
+	}
+	return n
+}
+
+// next returns the next instruction token, or io.EOF once the input is exhausted
+func (lx *lexer) next() (token, error) {
+	for {
+		buf, err := lx.r.Peek(lx.maxLen)
+		if len(buf) == 0 {
+			if err == nil || err == io.EOF {
+				return token{}, io.EOF
+			}
+			return token{}, &input.ParseError{Line: lx.line, Column: lx.column, Err: err}
+		}
+		if err != nil && err != io.EOF {
+			return token{}, &input.ParseError{Line: lx.line, Column: lx.column, Err: err}
+		}
+
+		if lx.first[buf[0]] {
+			for _, spec := range lx.specs {
+				n, args, ok := matchInstruction(buf, spec)
+				if !ok {
+					continue
+				}
+				tok := token{
+					name:   spec.name,
+					args:   args,
+					offset: lx.offset,
+					text:   string(buf[:n]),
+				}
+				lx.advance(n)
+				return tok, nil
+			}
+		}
+
+		// No instruction starts here, skip a byte of corruption
+		if lx.onSkip != nil {
+			lx.onSkip(buf[0])
+		}
+		lx.advance(1)
+	}
+}
+
+// advance consumes n bytes of input, which have already been peeked
+func (lx *lexer) advance(n int) {
+	buf, _ := lx.r.Peek(n)
+	for _, b := range buf {
+		if b == '\n' {
+			lx.line++
+			lx.column = 1
+		} else {
+			lx.column++
+		}
+	}
+	lx.r.Discard(n)
+	lx.offset += int64(n)
+}
+
+// matchInstruction checks whether buf starts with a valid spelling of the instruction
+// Returns the number of bytes matched and the parsed arguments
+func matchInstruction(buf []byte, spec instructionSpec) (int, []int, bool) {
+	if !bytes.HasPrefix(buf, []byte(spec.name)) {
+		return 0, nil, false
+	}
+	i := len(spec.name)
+
+	if i >= len(buf) || buf[i] != argOpen {
+		return 0, nil, false
+	}
+	i++
+
+	args := make([]int, 0, spec.arity)
+	for a := 0; a < spec.arity; a++ {
+		// Arguments after the first must be preceded by a separator
+		if a > 0 {
+			if i >= len(buf) || buf[i] != argSep {
+				return 0, nil, false
+			}
+			i++
+		}
+
+		n, val, ok := parseArg(buf[i:])
+		if !ok {
+			return 0, nil, false
+		}
+		args = append(args, val)
+		i += n
+	}
+
+	if i >= len(buf) || buf[i] != argClose {
+		return 0, nil, false
+	}
+	return i + 1, args, true
+}
+
+// parseArg parses a 1-3 digit number at the start of buf
+// Returns the number of bytes used and the value
+func parseArg(buf []byte) (int, int, bool) {
+	var n, val int
+	for n < len(buf) && n < maxArgDigits && isDigit(buf[n]) {
+		val = val*10 + int(buf[n]-'0')
+		n++
+	}
+	if n == 0 {
+		return 0, 0, false // Invalid if there are no digits
+	}
+	return n, val, true
+}
+
+// isDigit reports whether b is an ASCII digit
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func FuzzParseArg(f *testing.F) {
+	f.Add([]byte("123,4)"))
+	f.Add([]byte("1234"))
+	f.Add([]byte(",5"))
+	f.Add([]byte("007"))
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		n, val, ok := parseArg(buf)
+		if !ok {
+			if n != 0 {
+				t.Fatalf("used %d bytes of a failed argument", n)
+			}
+			return
+		}
+		if n < 1 || n > maxArgDigits || n > len(buf) {
+			t.Fatalf("used %d bytes of %d", n, len(buf))
+		}
+		if want, err := strconv.Atoi(string(buf[:n])); err != nil || val != want {
+			t.Fatalf("parsed %q as %d", buf[:n], val)
+		}
+	})
+}
+
+func FuzzMatchInstruction(f *testing.F) {
+	f.Add([]byte("mul(2,4)"))
+	f.Add([]byte("mul(4*"))
+	f.Add([]byte("mul ( 2 , 4 )"))
+	f.Add([]byte("don't()do()"))
+	f.Add([]byte("mul(1234,5)"))
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		for name, instr := range instructionSet {
+			spec := instructionSpec{name: name, arity: instr.arity}
+			n, args, ok := matchInstruction(buf, spec)
+			if !ok {
+				continue
+			}
+			if n > len(buf) || n > specMaxLen(spec) {
+				t.Fatalf("%s: matched %d bytes of %d", name, n, len(buf))
+			}
+			if len(args) != spec.arity {
+				t.Fatalf("%s: got %d arguments, want %d", name, len(args), spec.arity)
+			}
+			if !bytes.HasPrefix(buf, []byte(name+"(")) || buf[n-1] != argClose {
+				t.Fatalf("%s: matched %q", name, buf[:n])
+			}
+		}
+	})
+}
+
+func FuzzLexer(f *testing.F) {
+	f.Add([]byte("xmul(2,4)%&mul[3,7]!@^do_not_mul(5,5)+mul(32,64]then(mul(11,8)mul(8,5))"))
+	f.Add([]byte("xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))"))
+	f.Add([]byte("mul(1,\n2)mul(3,4)\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lx := newLexer(bytes.NewReader(data), instructionSet)
+		end := int64(0) // End of the last token, tokens mustn't overlap
+		for {
+			tok, err := lx.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tok.offset < end || tok.offset+int64(len(tok.text)) > int64(len(data)) {
+				t.Fatalf("token %q at %d overlaps or runs past the input", tok.text, tok.offset)
+			}
+			if got := string(data[tok.offset : tok.offset+int64(len(tok.text))]); got != tok.text {
+				t.Fatalf("token %q at %d, input there is %q", tok.text, tok.offset, got)
+			}
+			end = tok.offset + int64(len(tok.text))
+		}
+	})
+}
+
+// Examples from the puzzle
+const (
+	example1 = "xmul(2,4)%&mul[3,7]!@^do_not_mul(5,5)+mul(32,64]then(mul(11,8)mul(8,5))"
+	example2 = "xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))"
+)
+
+// sum runs the text through a machine executing the named instructions
+func sum(t *testing.T, text string, names ...string) int {
+	t.Helper()
+	m := newMachine(names...)
+	if err := runInput(strings.NewReader(text), nil, m); err != nil {
+		t.Fatal(err)
+	}
+	return m.sum
+}
+
+func TestExamples(t *testing.T) {
+	if got := sum(t, example1, mulInstr); got != 161 {
+		t.Errorf("part 1: got %d, want 161", got)
+	}
+	if got := sum(t, example2, mulInstr, doInstr, dontInstr); got != 48 {
+		t.Errorf("part 2: got %d, want 48", got)
+	}
+}
+
+func TestInstructions(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		part1 int
+		part2 int
+	}{
+		{name: "three digit arguments", text: "mul(123,4)", part1: 492, part2: 492},
+		{name: "four digit argument", text: "mul(1234,5)", part1: 0, part2: 0},
+		{name: "spaces", text: "mul ( 2 , 4 )mul(2, 4)", part1: 0, part2: 0},
+		{name: "wrong brackets", text: "mul[3,7]mul(3,7]", part1: 0, part2: 0},
+		{name: "nested in corruption", text: "mul(mul(2,3)", part1: 6, part2: 6},
+		{name: "dont", text: "don't()mul(2,3)", part1: 6, part2: 0},
+		{name: "dont then do", text: "don't()mul(2,3)do()mul(4,5)", part1: 26, part2: 20},
+		{name: "repeated toggles", text: "do()don't()don't()mul(2,3)do()do()mul(1,1)", part1: 7, part2: 1},
+		{name: "toggle with arguments", text: "don't(1)mul(2,3)do (5)", part1: 6, part2: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sum(t, tt.text, mulInstr); got != tt.part1 {
+				t.Errorf("part 1: got %d, want %d", got, tt.part1)
+			}
+			if got := sum(t, tt.text, mulInstr, doInstr, dontInstr); got != tt.part2 {
+				t.Errorf("part 2: got %d, want %d", got, tt.part2)
+			}
+		})
+	}
+}
+
+func TestLexerTokens(t *testing.T) {
+	lx := newLexer(strings.NewReader(example2), instructionSet)
+	var got []string
+	for {
+		tok, err := lx.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s@%d", tok.text, tok.offset))
+	}
+	want := []string{"mul(2,4)@1", "don't()@20", "mul(5,5)@28", "mul(11,8)@48", "do()@59", "mul(8,5)@64"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestLineBreaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		part1 int
+		part2 int
+	}{
+		{name: "between instructions", text: "mul(2,3)\nmul(4,5)\n", part1: 26, part2: 26},
+		{name: "after the name", text: "mul\n(2,3)", part1: 0, part2: 0},
+		{name: "after the bracket", text: "mul(\n2,3)", part1: 0, part2: 0},
+		{name: "inside an argument list", text: "mul(2,\n3)mul(1,1)", part1: 1, part2: 1},
+		{name: "crlf", text: "mul(2,3)\r\nmul(4,5)\r\n", part1: 26, part2: 26},
+		{name: "split toggle", text: "don't(\n)mul(2,3)", part1: 6, part2: 6},
+		{name: "toggle carries over", text: "don't()\nmul(2,3)\ndo()\nmul(1,1)", part1: 7, part2: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sum(t, tt.text, mulInstr); got != tt.part1 {
+				t.Errorf("part 1: got %d, want %d", got, tt.part1)
+			}
+			if got := sum(t, tt.text, mulInstr, doInstr, dontInstr); got != tt.part2 {
+				t.Errorf("part 2: got %d, want %d", got, tt.part2)
+			}
+		})
+	}
+}
+
+func TestLexerReadSizes(t *testing.T) {
+	// Long enough to refill the lexer's buffer several times
+	text := strings.Repeat(example2+"\n", 200)
+
+	tokens := func(r io.Reader) []token {
+		lx := newLexer(r, instructionSet)
+		var toks []token
+		for {
+			tok, err := lx.next()
+			if errors.Is(err, io.EOF) {
+				return toks
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			toks = append(toks, tok)
+		}
+	}
+
+	want := tokens(strings.NewReader(text))
+	if len(want) != 6*200 {
+		t.Fatalf("got %d tokens, want %d", len(want), 6*200)
+	}
+	readers := map[string]io.Reader{
+		"one byte": iotest.OneByteReader(strings.NewReader(text)),
+		"half":     iotest.HalfReader(strings.NewReader(text)),
+		"data err": iotest.DataErrReader(strings.NewReader(text)),
+	}
+	for name, r := range readers {
+		if got := tokens(r); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: tokens differ from reading the whole input", name)
+		}
+	}
+}
+
+// trace runs the text through both parts' machines, writing the trace in the given format
+func trace(t *testing.T, text, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	tr, err := newTracer(&buf, format, newMachine(mulInstr), newMachine(mulInstr, doInstr, dontInstr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runInput(strings.NewReader(text), tr, tr.part1, tr.part2); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestTraceJSON(t *testing.T) {
+	var got []traceEvent
+	for _, line := range strings.Split(strings.TrimSuffix(trace(t, example2, traceFormatJSON), "\n"), "\n") {
+		var ev traceEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("decoding %q: %v", line, err)
+		}
+		got = append(got, ev)
+	}
+
+	want := []traceEvent{
+		{Kind: eventMulEnabled, Instruction: mulInstr, Args: []int{2, 4}, Text: "mul(2,4)", Offset: 1, Part1Total: 8, Part2Total: 8},
+		{Kind: eventDont, Instruction: dontInstr, Args: []int{}, Text: "don't()", Offset: 20, Part1Total: 8, Part2Total: 8},
+		{Kind: eventMulDisabled, Instruction: mulInstr, Args: []int{5, 5}, Text: "mul(5,5)", Offset: 28, Part1Total: 33, Part2Total: 8},
+		{Kind: eventMulDisabled, Instruction: mulInstr, Args: []int{11, 8}, Text: "mul(11,8)", Offset: 48, Part1Total: 121, Part2Total: 8},
+		{Kind: eventDo, Instruction: doInstr, Args: []int{}, Text: "do()", Offset: 59, Part1Total: 121, Part2Total: 8},
+		{Kind: eventMulEnabled, Instruction: mulInstr, Args: []int{8, 5}, Text: "mul(8,5)", Offset: 64, Part1Total: 161, Part2Total: 48},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Args == nil {
+			got[i].Args = []int{} // Arguments of a toggle decode as null or []
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("event %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTraceANSI(t *testing.T) {
+	got := trace(t, "a mul(2,4)\ndon't()mul(1,1)?", traceFormatANSI)
+	want := "a " +
+		ansiGreen + "mul(2,4)" + ansiReset + ansiDim + "{@2 p1=8 p2=8}" + ansiReset +
+		"\n" +
+		ansiMagenta + "don't()" + ansiReset + ansiDim + "{@11 p1=8 p2=8}" + ansiReset +
+		ansiRed + "mul(1,1)" + ansiReset + ansiDim + "{@18 p1=9 p2=8}" + ansiReset +
+		"?\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTraceFormat(t *testing.T) {
+	if _, err := newTracer(io.Discard, "html", newMachine(), newMachine()); err == nil {
+		t.Fatal("expected an error for an unknown trace format")
+	}
+}
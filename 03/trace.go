@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Trace output formats
+const (
+	traceFormatANSI string = "ansi" // Input with highlighted instructions
+	traceFormatJSON string = "json" // One JSON event per instruction
+)
+
+// ANSI escape codes used to highlight instructions
+const (
+	ansiReset   string = "\x1b[0m"
+	ansiDim     string = "\x1b[2m"
+	ansiBold    string = "\x1b[1m"    // Any other instruction
+	ansiGreen   string = "\x1b[1;32m" // Enabled mul
+	ansiRed     string = "\x1b[1;31m" // Disabled mul
+	ansiCyan    string = "\x1b[1;36m" // do()
+	ansiMagenta string = "\x1b[1;35m" // don't()
+)
+
+// Kinds of trace events, one per highlight colour
+const (
+	eventMulEnabled  string = "mul-enabled"  // mul counted by part 2
+	eventMulDisabled string = "mul-disabled" // mul counted by part 1 only
+	eventDo          string = "do"
+	eventDont        string = "dont"
+	eventOther       string = "other" // Any other instruction added to the instruction set
+)
+
+// traceEvent is a recognized instruction and the running totals after it ran
+type traceEvent struct {
+	Kind        string `json:"kind"`
+	Instruction string `json:"instruction"`
+	Args        []int  `json:"args"`
+	Text        string `json:"text"`
+	Offset      int64  `json:"offset"`
+	Part1Total  int    `json:"part1_total"`
+	Part2Total  int    `json:"part2_total"`
+}
+
+// tracer writes an execution trace of the part 1 and part 2 machines
+type tracer struct {
+	w            *bufio.Writer
+	format       string
+	part1, part2 *machine
+}
+
+// newTracer creates a tracer writing to w in the given format
+func newTracer(w io.Writer, format string, part1, part2 *machine) (*tracer, error) {
+	if format != traceFormatANSI && format != traceFormatJSON {
+		return nil, fmt.Errorf("unknown trace format %q, expected %s or %s", format, traceFormatANSI, traceFormatJSON)
+	}
+	return &tracer{
+		w:      bufio.NewWriter(w),
+		format: format,
+		part1:  part1,
+		part2:  part2,
+	}, nil
+}
+
+// skip is called with every byte of corruption between instructions
+func (tr *tracer) skip(b byte) {
+	if tr.format == traceFormatANSI {
+		tr.w.WriteByte(b)
+	}
+}
+
+// token is called with every instruction once both machines have executed it
+func (tr *tracer) token(t token) error {
+	ev := traceEvent{
+		Kind:        eventKind(t, tr.part2),
+		Instruction: t.name,
+		Args:        t.args,
+		Text:        t.text,
+		Offset:      t.offset,
+		Part1Total:  tr.part1.sum,
+		Part2Total:  tr.part2.sum,
+	}
+
+	if tr.format == traceFormatJSON {
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("error encoding trace event: %w", err)
+		}
+		tr.w.Write(b)
+		tr.w.WriteByte('\n')
+		return nil
+	}
+
+	// Highlight the instruction, then annotate it with its offset and the running totals
+	fmt.Fprintf(tr.w, "%s%s%s%s{@%d p1=%d p2=%d}%s",
+		eventColour(ev.Kind), ev.Text, ansiReset,
+		ansiDim, ev.Offset, ev.Part1Total, ev.Part2Total, ansiReset)
+	return nil
+}
+
+// flush writes any buffered trace output
+func (tr *tracer) flush() error {
+	if tr.format == traceFormatANSI {
+		tr.w.WriteByte('\n')
+	}
+	return tr.w.Flush()
+}
+
+// eventKind classifies an instruction using the state of the part 2 machine
+func eventKind(t token, part2 *machine) string {
+	switch t.name {
+	case mulInstr:
+		if part2.enabled {
+			return eventMulEnabled
+		}
+		return eventMulDisabled
+	case doInstr:
+		return eventDo
+	case dontInstr:
+		return eventDont
+	}
+	return eventOther
+}
+
+// eventColour returns the highlight colour for a kind of event
+func eventColour(kind string) string {
+	switch kind {
+	case eventMulEnabled:
+		return ansiGreen
+	case eventMulDisabled:
+		return ansiRed
+	case eventDo:
+		return ansiCyan
+	case eventDont:
+		return ansiMagenta
+	}
+	return ansiBold
+}
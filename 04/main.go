@@ -1,9 +1,15 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 4: Challenge
@@ -13,8 +19,6 @@ import (
 const (
 	filename     string = "input.txt"
 	solutionXMAS string = "XMAS"
-	solutionMAS  string = "MAS"
-	solutionSAM  string = "SAM"
 )
 
 // direction represents movement in the grid
@@ -25,18 +29,27 @@ type direction struct {
 // Movement directions for part 1 and part 2 of the puzzle
 var (
 	allDirections = []direction{
-		{-1, -1}, // Top-left
-		{-1, 0},  // Top
-		{-1, 1},  // Top-right
-		{0, -1},  // Left
-		{0, 1},   // Right
-		{1, -1},  // Bottom-left
-		{1, 0},   // Bottom
-		{1, 1},   // Bottom-right
-	}
-	diagonalDirections = []direction{
-		{-1, -1}, // Top-left
-		{-1, 1},  // Top-right
+		{-1, -1}, // Up-left
+		{-1, 0},  // Left
+		{-1, 1},  // Down-left
+		{0, -1},  // Up
+		{0, 1},   // Down
+		{1, -1},  // Up-right
+		{1, 0},   // Right
+		{1, 1},   // Down-right
+	}
+
+	// Directions by the compass names accepted by -directions, north is up
+	compass = map[string]direction{
+		"n": {0, -1}, "ne": {1, -1}, "e": {1, 0}, "se": {1, 1},
+		"s": {0, 1}, "sw": {-1, 1}, "w": {-1, 0}, "nw": {-1, -1},
+	}
+
+	// Named sets of directions accepted by -directions
+	directionSets = map[string]string{
+		"all":      "n,ne,e,se,s,sw,w,nw",
+		"straight": "n,e,s,w",
+		"diagonal": "ne,se,sw,nw",
 	}
 )
 
@@ -47,207 +60,139 @@ type cell struct {
 }
 
 func main() {
-	// Read the puzzle grid from the file and display it
-	puzzle := readInput(filename)
-
-	// Part 1: Find all occurrences of the word "XMAS" in the grid
-	solutions1 := findSolutions(puzzle, solutionXMAS, allDirections)
-	fmt.Println(len(solutions1)) // Print the number of solutions found
-
-	// Part 2: Find occurrences of "MAS" and "SAM" in diagonal directions
-	solutions2MAS := findSolutions(puzzle, solutionMAS, diagonalDirections)
-	solutions2SAM := findSolutions(puzzle, solutionSAM, diagonalDirections)
-
-	// Combine all solutions for part 2
-	solutions2 := append(solutions2MAS, solutions2SAM...)
-
-	// Union solutions by their middle value
-	unioned := unionByMiddleVal(solutions2, solutions2)
-
-	// count valid groups - a valid group contains two unique solutions that contain the same middle cell
-	fmt.Println(countValid(unioned))
-}
-
-// readInput reads the grid from the file and converts it to a 2D slice of cells
-func readInput(fname string) [][]cell {
-	cells := make([][]cell, 0)
-
-	file, err := os.Open(fname)
+	directionNames := flag.String("directions", "all", "directions XMAS is read in for part 1: all, straight, diagonal or a comma separated list of n, ne, e, se, s, sw, w and nw")
+	templateFile := flag.String("template", "", "file of templates to count for part 2 instead of the X-MAS cross, '.' matches any cell")
+	rotations := flag.Bool("rotations", false, "also match rotations of templates read from -template")
+	reflections := flag.Bool("reflections", false, "also match mirror images of templates read from -template")
+	renderFormat := flag.String("render", "", "render the grid with matches highlighted: ansi or html")
+	renderLayers := flag.String("layers", layerPart1+","+layerPart2, "comma separated layers to highlight when rendering: part1, part2")
+	heatmap := flag.Bool("heatmap", false, "when rendering, colour cells by how many matches they are part of")
+	renderOut := flag.String("out", "", "file to write the rendering to, defaults to stderr")
+	run := result.Main(4, filename)
+
+	dirs, err := parseDirections(*directionNames)
 	if err != nil {
-		fmt.Println("Error opening file:", err)
-		return nil
+		slog.Error("parsing directions", "err", err)
+		os.Exit(1)
 	}
-	defer file.Close()
 
-	reader := bufio.NewReader(file)
-	var row []cell
-	var i, j int
+	// Read the puzzle grid from the file
+	puzzle, err := readInput(filename)
+	if err != nil {
+		run.Fatal(err)
+	}
 
-	for {
-		char, _, err := reader.ReadRune()
+	// Part 2 counts the X-MAS cross in any orientation, unless other templates are given
+	crosses := newTemplateMatcher([]template{xmasTemplate}, true, false)
+	if *templateFile != "" {
+		templates, err := readTemplates(*templateFile)
 		if err != nil {
-			if err.Error() == "EOF" {
-				if len(row) > 0 {
-					cells = append(cells, row) // Append the last row
-				}
-				break
-			}
-			fmt.Println("Error reading file:", err)
-			return nil
-		}
-
-		if char == '\n' { // Handle newlines as row separators
-			cells = append(cells, row)
-			row = make([]cell, 0)
-			j++ // Move to the next row
-			i = 0
-			continue
+			run.Fatal(err)
 		}
-
-		// Add the character as a cell to the current row
-		row = append(row, cell{x: i, y: j, val: char})
-		i++
+		crosses = newTemplateMatcher(templates, *rotations, *reflections)
 	}
-	return cells
-}
 
-// /////////////
-// Part 1 & 2 //
-// ////////// //
-
-// findSolutions finds all paths in the grid that match the target word
-func findSolutions(puzzle [][]cell, solution string, validDirections []direction) [][]cell {
-	var solutions [][]cell
-
-	// Iterate over all cells in the grid
-	for i, row := range puzzle {
-		for j := range row {
-			// If the cell matches the first character, start exploring paths
-			if puzzle[i][j].val == rune(solution[0]) {
-				path := []cell{puzzle[i][j]} // Start a new path
-				for _, dir := range validDirections {
-					// Explore all paths in the specified directions
-					solutions = dfs(puzzle, i, j, solution[1:], path, dir, solutions)
-				}
-			}
-		}
-	}
-	return solutions
-}
+	// Part 1: Find all occurrences of the word "XMAS" in the grid
+	xmas := newWordSearch([]string{solutionXMAS})
+	run.Part(1, func() (any, error) {
+		return xmas.count(puzzle, dirs), nil
+	})
 
-// dfs explores paths recursively to find matches for the target word
-func dfs(puzzle [][]cell, x, y int, remainingSolution string, path []cell, dir direction, solutions [][]cell) [][]cell {
-	// Base case: If no more characters to match, add the path to solutions
-	if len(remainingSolution) == 0 {
-		solutions = append(solutions, path)
-		return solutions
-	}
+	// Part 2: Count placements of the templates
+	run.Part(2, func() (any, error) {
+		return crosses.count(puzzle), nil
+	})
 
-	// Calculate the coordinates of the next cell in the current direction
-	nx, ny := x+dir.dx, y+dir.dy
+	run.Finish()
 
-	// Check bounds and character match for the next cell
-	if inBounds(nx, ny, puzzle) && puzzle[nx][ny].val == rune(remainingSolution[0]) && !visited(path, puzzle[nx][ny]) {
-		newPath := append([]cell{}, path...)      // Create a new path
-		newPath = append(newPath, puzzle[nx][ny]) // Add the next cell
-		// Continue exploring with updated path and remaining solution
-		solutions = dfs(puzzle, nx, ny, remainingSolution[1:], newPath, dir, solutions)
+	if *renderFormat == "" {
+		return
 	}
-	return solutions
-}
 
-// inBounds checks if a cell is within the grid's boundaries
-func inBounds(x, y int, puzzle [][]cell) bool {
-	return x >= 0 && x < len(puzzle) && y >= 0 && y < len(puzzle[0])
-}
-
-// visited checks if a cell is already part of the current path
-func visited(path []cell, c cell) bool {
-	for _, p := range path {
-		if p.x == c.x && p.y == c.y {
-			return true
-		}
+	// Render both parts' matches over the grid
+	layers, err := parseLayers(*renderLayers)
+	if err != nil {
+		slog.Error("parsing layers", "err", err)
+		os.Exit(1)
+	}
+	h := newHeat(puzzle, xmas.find(puzzle, dirs), crosses, crosses.find(puzzle))
+	opts := renderOptions{format: *renderFormat, layers: layers, heatmap: *heatmap}
+	if err := writeRender(*renderOut, puzzle, h, opts); err != nil {
+		slog.Error("rendering", "err", err)
+		os.Exit(1)
 	}
-	return false
 }
 
-// //////////
-// Part 2  //
-// //////////
-
-// unionByMiddleVal groups paths by the middle cell of their slices
-func unionByMiddleVal(setA, setB [][]cell) map[cell][][]cell {
-	solutions := make(map[cell][][]cell)
-
-	// Add paths from setA grouped by their middle cell
-	for i := range setA {
-		middleCell := setA[i][1]
-		solutions[middleCell] = append(solutions[middleCell], setA[i])
-	}
-
-	// Add paths from setB if they match and have distinct first cells
-	for i := range setB {
-		middleCell := setB[i][1]
-		// check:
-		//    1) the middle cell was a part of one of setA's solutions
-		//    2) the middle cell is not a part of a solution already included in setA's solutions
-		if _, ok := solutions[middleCell]; ok && setA[i][0] != solutions[middleCell][0][0] {
-			// append to solution, givng an X-MAS
-			solutions[middleCell] = append(solutions[middleCell], setB[i])
+// parseDirections parses a comma separated list of compass directions and named sets, e.g. "e,s" or "diagonal"
+func parseDirections(names string) ([]direction, error) {
+	var dirs []direction
+	seen := make(map[direction]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		expanded := []string{name}
+		if set, ok := directionSets[name]; ok {
+			expanded = strings.Split(set, ",")
+		}
+		for _, n := range expanded {
+			dir, ok := compass[n]
+			if !ok {
+				return nil, fmt.Errorf("unknown direction %q, expected all, straight, diagonal, n, ne, e, se, s, sw, w or nw", name)
+			}
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
 		}
 	}
-
-	return solutions
+	return dirs, nil
 }
 
-// countValid counts groups with at least two unique solutions that share the same middle cell
-func countValid(us map[cell][][]cell) int {
-	var count int
-	for _, solution := range us {
-		if len(solution) >= 2 {
-			count++
-		}
+// writeRender renders the grid to the named file, or stderr if fname is empty
+// Stdout is left to the answers, so it stays parseable whatever the output format
+func writeRender(fname string, puzzle [][]cell, h heat, opts renderOptions) error {
+	if fname == "" {
+		return render(os.Stderr, puzzle, h, opts)
 	}
-	return count
-}
 
-// //////////////
-// Debug Funcs //
-// ////////// //
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("error creating file [%s]: %w", fname, err)
+	}
+	defer f.Close()
 
-// printUnionSolutions displays the grouped solutions
-func printUnionSolutions(us map[cell][][]cell) {
-	for middleCell, solution := range us {
-		if len(solution) > 1 {
-			fmt.Printf("Middle Cell: (%s)\n%s\n", cellToString(middleCell), solutionToString(solution))
-		}
+	if err := render(f, puzzle, h, opts); err != nil {
+		return err
 	}
+	return f.Close()
 }
 
-// cellToString formats a cell as a readable string
-func cellToString(c cell) string {
-	return fmt.Sprintf("[%s]: [%d,%d]", string(c.val), c.x, c.y)
-}
+// readInput reads the grid from the file and converts it to a 2D slice of cells
+func readInput(fname string) ([][]cell, error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file [%s]: %w", fname, err)
+	}
+	defer file.Close()
 
-// solutionToString formats a solution as a readable string
-func solutionToString(s [][]cell) string {
-	var export string
-	for _, row := range s {
-		export += "\t"
-		for _, cell := range row {
-			export += fmt.Sprintf("%s ", cellToString(cell))
-		}
-		export += "\n"
+	cells, err := parseGrid(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
 	}
-	return export
+	return cells, nil
 }
 
-// puzzleToString prints the grid to the console
-func puzzleToString(s [][]cell) {
-	for _, row := range s {
-		for _, cell := range row {
-			fmt.Print(string(cell.val))
+// Parses a rectangular grid of runes into a 2D slice of cells
+func parseGrid(r io.Reader) ([][]cell, error) {
+	grid, err := input.Grid(r)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([][]cell, len(grid))
+	for j, row := range grid {
+		cells[j] = make([]cell, len(row))
+		for i, char := range row {
+			cells[j][i] = cell{x: i, y: j, val: char}
 		}
-		fmt.Println()
 	}
+	return cells, nil
 }
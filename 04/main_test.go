@@ -0,0 +1,428 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+// Example from the puzzle
+const example = `MMMSXXMASM
+MSAMXMSMSA
+AMXSXMAAMM
+MSAMASMSMX
+XMASAMXAMM
+XXAMMXXAMA
+SMSMSASXSS
+SAXAMASAAA
+MAMMMXMMMM
+MXMXAXMASX
+`
+
+func FuzzParseGrid(f *testing.F) {
+	f.Add(example)
+	f.Add("XMAS\nXMA\n")
+	f.Add("\n")
+	f.Add("XM\n\nAS\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		puzzle, err := parseGrid(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		for y, row := range puzzle {
+			if len(row) != len(puzzle[0]) {
+				t.Fatalf("row %d has %d cells, row 0 has %d", y, len(row), len(puzzle[0]))
+			}
+		}
+		newWordSearch([]string{solutionXMAS}).count(puzzle, allDirections)
+		newTemplateMatcher([]template{xmasTemplate}, true, false).count(puzzle)
+	})
+}
+
+func FuzzParseTemplates(f *testing.F) {
+	f.Add("M.S\n.A.\nM.S\n")
+	f.Add("XMAS\n\nX\nM\nA\nS\n")
+	f.Add("ab\nc\n\n\n")
+	f.Add("\n\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		templates, err := parseTemplates(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		puzzle, err := parseGrid(strings.NewReader(example))
+		if err != nil {
+			t.Fatal(err)
+		}
+		newTemplateMatcher(templates, true, true).count(puzzle)
+	})
+}
+
+// grid parses a grid for a test
+func grid(t *testing.T, text string) [][]cell {
+	t.Helper()
+	puzzle, err := parseGrid(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return puzzle
+}
+
+func TestWordSearchExample(t *testing.T) {
+	if got := newWordSearch([]string{solutionXMAS}).count(grid(t, example), allDirections); got != 18 {
+		t.Fatalf("got %d, want 18", got)
+	}
+}
+
+func TestWordSearch(t *testing.T) {
+	right := []direction{{1, 0}}
+	tests := []struct {
+		name  string
+		grid  string
+		words []string
+		dirs  []direction
+		want  int
+	}{
+		{name: "overlapping", grid: "AAAA\n", words: []string{"AA"}, dirs: right, want: 3},
+		{name: "suffixes", grid: "ABC\n", words: []string{"ABC", "BC", "C"}, dirs: right, want: 3},
+		{name: "prefixes", grid: "ABC\n", words: []string{"A", "AB", "ABC"}, dirs: right, want: 3},
+		{name: "duplicate words", grid: "XMAS\n", words: []string{"XMAS", "XMAS", ""}, dirs: right, want: 1},
+		{name: "palindrome both ways", grid: "ABA\n", words: []string{"ABA"}, dirs: []direction{{1, 0}, {-1, 0}}, want: 2},
+		{name: "non-ascii", grid: "ÅÄÖ\nÄÖÅ\n", words: []string{"ÄÖ"}, dirs: allDirections, want: 3},
+		{name: "diagonal", grid: "X...\n.M..\n..A.\n...S\n", words: []string{"XMAS"}, dirs: allDirections, want: 1},
+		{name: "anti-diagonal", grid: "...S\n..A.\n.M..\nX...\n", words: []string{"XMAS"}, dirs: allDirections, want: 1},
+		{name: "no directions", grid: "XMAS\n", words: []string{"XMAS"}, dirs: nil, want: 0},
+		{name: "still", grid: "X\n", words: []string{"X"}, dirs: []direction{{0, 0}}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newWordSearch(tt.words).count(grid(t, tt.grid), tt.dirs); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// naiveCount counts words by checking every start cell and direction
+func naiveCount(puzzle [][]cell, words []string, dirs []direction) int {
+	var n int
+	for _, w := range words {
+		for y := range puzzle {
+			for x := range puzzle[y] {
+				for _, d := range dirs {
+					ok := true
+					for i, r := range []rune(w) {
+						px, py := x+i*d.dx, y+i*d.dy
+						if !inBounds(px, py, puzzle) || puzzle[py][px].val != r {
+							ok = false
+							break
+						}
+					}
+					if ok {
+						n++
+					}
+				}
+			}
+		}
+	}
+	return n
+}
+
+func TestWordSearchMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4, 4))
+	words := []string{"XMAS", "MAS", "AS", "SAMX", "XX"}
+	ws := newWordSearch(words)
+	for i := 0; i < 50; i++ {
+		var sb strings.Builder
+		w, h := 1+rng.IntN(12), 1+rng.IntN(12)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				sb.WriteByte("XMAS"[rng.IntN(4)])
+			}
+			sb.WriteByte('\n')
+		}
+		puzzle := grid(t, sb.String())
+
+		want := naiveCount(puzzle, words, allDirections)
+		if got := ws.count(puzzle, allDirections); got != want {
+			t.Fatalf("grid %d: got %d, want %d\n%s", i, got, want, sb.String())
+		}
+
+		// Every match spells its word along its path
+		for _, m := range ws.find(puzzle, allDirections) {
+			var spelt []rune
+			for _, c := range m.path(puzzle) {
+				spelt = append(spelt, c.val)
+			}
+			if string(spelt) != m.word {
+				t.Fatalf("match of %q spells %q", m.word, string(spelt))
+			}
+		}
+	}
+}
+
+func TestWordSearchWideAlphabet(t *testing.T) {
+	// More distinct runes than fit in a byte, so the grid is translated into wider symbols
+	var dictionary []string
+	var row strings.Builder
+	for i := 0; i < 300; i++ {
+		r := rune(0x4e00 + i)
+		dictionary = append(dictionary, string([]rune{r, r + 1}))
+		row.WriteRune(r)
+	}
+	puzzle := grid(t, row.String()+"\n")
+	ws := newWordSearch(dictionary)
+	if ws.alphabet <= 256 {
+		t.Fatalf("alphabet of %d symbols fits in a byte", ws.alphabet)
+	}
+	right := []direction{{1, 0}}
+	if got, want := ws.count(puzzle, right), naiveCount(puzzle, dictionary, right); got != want || got != 299 {
+		t.Fatalf("got %d, want %d and 299", got, want)
+	}
+}
+
+func TestParseDirections(t *testing.T) {
+	tests := []struct {
+		names string
+		want  []direction
+		err   bool
+	}{
+		{names: "e", want: []direction{{1, 0}}},
+		{names: "e, s", want: []direction{{1, 0}, {0, 1}}},
+		{names: "straight", want: []direction{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}},
+		{names: "diagonal,ne", want: []direction{{1, -1}, {1, 1}, {-1, 1}, {-1, -1}}},
+		{names: "n,n", want: []direction{{0, -1}}},
+		{names: "up", err: true},
+		{names: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.names, func(t *testing.T) {
+			got, err := parseDirections(tt.names)
+			if (err != nil) != tt.err {
+				t.Fatalf("got error %v, want error %t", err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Every direction in every set is one of the eight, and all of them are the puzzle's
+	all, err := parseDirections("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(allDirections) {
+		t.Errorf("got %d directions for all, want %d", len(all), len(allDirections))
+	}
+}
+
+func BenchmarkWordSearch(b *testing.B) {
+	const side = 1000
+	rng := rand.New(rand.NewPCG(4, 4))
+	var sb strings.Builder
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			sb.WriteByte("XMAS"[rng.IntN(4)])
+		}
+		sb.WriteByte('\n')
+	}
+	puzzle, err := parseGrid(strings.NewReader(sb.String()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	ws := newWordSearch([]string{solutionXMAS})
+
+	// Throughput is in cells of the grid per second
+	b.SetBytes(side * side)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ws.count(puzzle, allDirections)
+	}
+}
+
+func TestTemplateExample(t *testing.T) {
+	puzzle := grid(t, example)
+	for _, reflections := range []bool{false, true} {
+		if got := newTemplateMatcher([]template{xmasTemplate}, true, reflections).count(puzzle); got != 9 {
+			t.Errorf("reflections %t: got %d, want 9", reflections, got)
+		}
+	}
+}
+
+func TestTemplateVariants(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        []string
+		rotations   bool
+		reflections bool
+		want        int
+	}{
+		{name: "x-mas", rows: xmasTemplate.rows, rotations: true, want: 4},
+		{name: "x-mas mirrored", rows: xmasTemplate.rows, rotations: true, reflections: true, want: 4},
+		{name: "x-mas as given", rows: xmasTemplate.rows, want: 1},
+		{name: "x-mas mirrored only", rows: xmasTemplate.rows, reflections: true, want: 2},
+		{name: "single cell", rows: []string{"A"}, rotations: true, reflections: true, want: 1},
+		{name: "line", rows: []string{"AB"}, rotations: true, reflections: true, want: 4},
+		{name: "repeated line", rows: []string{"AA"}, rotations: true, reflections: true, want: 2},
+		{name: "checkerboard", rows: []string{"AB", "BA"}, rotations: true, reflections: true, want: 2},
+		{name: "corner", rows: []string{"AB", "C."}, rotations: true, want: 4},
+		{name: "corner mirrored", rows: []string{"AB", "C."}, rotations: true, reflections: true, want: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := newTemplateMatcher([]template{{rows: tt.rows}}, tt.rotations, tt.reflections)
+			if got := len(tm.variants); got != tt.want {
+				t.Fatalf("got %d variants %v, want %d", got, tm.variants, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateTransforms(t *testing.T) {
+	corner := template{rows: []string{"AB", "C"}} // Short rows are padded with wildcards
+	if got, want := corner.rotate().rows, []string{"CA", ".B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rotate: got %q, want %q", got, want)
+	}
+	if got, want := corner.reflect().rows, []string{"BA", ".C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reflect: got %q, want %q", got, want)
+	}
+	if got, want := corner.rotate().rotate().rotate().rotate().rows, []string{"AB", "C."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("four rotations: got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateSymmetricCountedOnce(t *testing.T) {
+	// Every variant of a palindrome that matches is the same placement
+	tm := newTemplateMatcher([]template{{rows: []string{"ABA"}}}, true, true)
+	if got := tm.count(grid(t, "ABA\n")); got != 1 {
+		t.Fatalf("got %d, want 1", got)
+	}
+	if got := tm.count(grid(t, "A\nB\nA\n")); got != 1 {
+		t.Fatalf("vertical: got %d, want 1", got)
+	}
+}
+
+func TestParseLayers(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]bool
+		wantErr bool
+	}{
+		{in: "part1,part2", want: map[string]bool{layerPart1: true, layerPart2: true}},
+		{in: " part2 ", want: map[string]bool{layerPart2: true}},
+		{in: "part1,part1", want: map[string]bool{layerPart1: true}},
+		{in: "part3", wantErr: true},
+		{in: "part1,", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseLayers(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: got error %v, want error %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// renderGrid renders a grid with its XMAS words and X-MAS crosses
+func renderGrid(t *testing.T, text string, opts renderOptions) string {
+	t.Helper()
+	puzzle := grid(t, text)
+	tm := newTemplateMatcher([]template{xmasTemplate}, true, false)
+	h := newHeat(puzzle, newWordSearch([]string{solutionXMAS}).find(puzzle, allDirections), tm, tm.find(puzzle))
+
+	var sb strings.Builder
+	if err := render(&sb, puzzle, h, opts); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestNewHeat(t *testing.T) {
+	// The word runs down the middle column, through the centre of the cross
+	puzzle := grid(t, ".X.\nMMS\n.A.\nMSS\n")
+	tm := newTemplateMatcher([]template{xmasTemplate}, true, false)
+	h := newHeat(puzzle, newWordSearch([]string{solutionXMAS}).find(puzzle, allDirections), tm, tm.find(puzzle))
+
+	wantPart1 := [][]int{{0, 1, 0}, {0, 1, 0}, {0, 1, 0}, {0, 1, 0}}
+	wantPart2 := [][]int{{0, 0, 0}, {1, 0, 1}, {0, 1, 0}, {1, 0, 1}}
+	if !reflect.DeepEqual(h.part1, wantPart1) {
+		t.Errorf("part 1: got %v, want %v", h.part1, wantPart1)
+	}
+	if !reflect.DeepEqual(h.part2, wantPart2) {
+		t.Errorf("part 2: got %v, want %v", h.part2, wantPart2)
+	}
+}
+
+func TestRenderANSI(t *testing.T) {
+	both := map[string]bool{layerPart1: true, layerPart2: true}
+	got := renderGrid(t, "XMASX\n", renderOptions{format: renderANSI, layers: both})
+	want := ansiPart1 + "X" + ansiReset + ansiPart1 + "M" + ansiReset + ansiPart1 + "A" + ansiReset + ansiPart1 + "S" + ansiReset +
+		ansiDim + "X" + ansiReset + "\n" +
+		ansiPart1 + "XMAS" + ansiReset + " " + ansiPart2 + "X-MAS" + ansiReset + " " + ansiBoth + "both" + ansiReset + "\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Hiding the part 1 layer dims its cells
+	got = renderGrid(t, "XMASX\n", renderOptions{format: renderANSI, layers: map[string]bool{layerPart2: true}})
+	if !strings.HasPrefix(got, ansiDim+"X"+ansiReset+ansiDim+"M"+ansiReset) {
+		t.Errorf("hidden layer: got %q", got)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	both := map[string]bool{layerPart1: true, layerPart2: true}
+	got := renderGrid(t, "XMAS<\n", renderOptions{format: renderHTML, layers: both})
+	for _, want := range []string{
+		`<span class="part1" title="(0,0) XMAS: 1, X-MAS: 0">X</span>`,
+		`<span class="dim" title="(4,0) XMAS: 0, X-MAS: 0">&lt;</span>`,
+		"</html>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+
+	got = renderGrid(t, "XMAS\n", renderOptions{format: renderHTML, layers: both, heatmap: true})
+	if want := `<span class="heat" style="background: ` + heatRGB[len(heatRGB)-1] + `"`; !strings.Contains(got, want) {
+		t.Errorf("heatmap: missing %q in\n%s", want, got)
+	}
+}
+
+func TestRenderFormat(t *testing.T) {
+	if err := render(io.Discard, nil, heat{}, renderOptions{format: "svg"}); err == nil {
+		t.Fatal("expected an error for an unknown render format")
+	}
+}
+
+func TestHeatIndex(t *testing.T) {
+	last := len(heatANSI) - 1
+	tests := []struct{ count, maxCount, want int }{
+		{count: 1, maxCount: 1, want: last},
+		{count: 1, maxCount: 5, want: 0},
+		{count: 3, maxCount: 5, want: last / 2},
+		{count: 5, maxCount: 5, want: last},
+	}
+	for _, tt := range tests {
+		if got := heatIndex(tt.count, tt.maxCount); got != tt.want {
+			t.Errorf("heatIndex(%d, %d): got %d, want %d", tt.count, tt.maxCount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Render output formats
+const (
+	renderANSI string = "ansi"
+	renderHTML string = "html"
+)
+
+// Render layers, one per part of the puzzle
+const (
+	layerPart1 string = "part1" // Cells in an XMAS word
+	layerPart2 string = "part2" // Cells in an X-MAS cross
+)
+
+// ANSI escape codes for rendering
+const (
+	ansiReset  string = "\x1b[0m"
+	ansiDim    string = "\x1b[2m"
+	ansiPart1  string = "\x1b[1;33m" // Yellow
+	ansiPart2  string = "\x1b[1;36m" // Cyan
+	ansiBoth   string = "\x1b[1;35m" // Magenta
+	ansiHeatFg string = "\x1b[1;30m" // Black text over heatmap backgrounds
+)
+
+// Heatmap colours from least to most matches, as xterm 256 colour indices and their RGB equivalents
+var (
+	heatANSI = []int{22, 28, 34, 40, 76, 112, 148, 184, 220, 214, 208, 202, 196}
+	heatRGB  = []string{
+		"#005f00", "#008700", "#00af00", "#00d700", "#5fd700", "#87d700", "#afd700",
+		"#d7d700", "#ffd700", "#ffaf00", "#ff8700", "#ff5f00", "#ff0000",
+	}
+)
+
+// heat counts how many matches of each layer every cell participates in
+type heat struct {
+	part1 [][]int
+	part2 [][]int
+}
+
+// renderOptions controls what the renderer draws
+type renderOptions struct {
+	format  string          // ansi or html
+	layers  map[string]bool // Layers to highlight
+	heatmap bool            // Colour cells by match count instead of by layer
+}
+
+// newHeat counts the cells covered by part 1 word matches and part 2 template placements
+func newHeat(puzzle [][]cell, words []match, tm *templateMatcher, placements []placement) heat {
+	h := heat{part1: make([][]int, len(puzzle)), part2: make([][]int, len(puzzle))}
+	for y, row := range puzzle {
+		h.part1[y] = make([]int, len(row))
+		h.part2[y] = make([]int, len(row))
+	}
+
+	for _, m := range words {
+		for _, c := range m.path(puzzle) {
+			h.part1[c.y][c.x]++
+		}
+	}
+	for _, p := range placements {
+		for _, c := range tm.placementCells(puzzle, p) {
+			h.part2[c.y][c.x]++
+		}
+	}
+	return h
+}
+
+// parseLayers parses a comma separated list of layer names
+func parseLayers(s string) (map[string]bool, error) {
+	layers := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name != layerPart1 && name != layerPart2 {
+			return nil, fmt.Errorf("unknown layer %q, expected %s or %s", name, layerPart1, layerPart2)
+		}
+		layers[name] = true
+	}
+	return layers, nil
+}
+
+// render draws the grid with cells highlighted according to the options
+func render(w io.Writer, puzzle [][]cell, h heat, opts renderOptions) error {
+	bw := bufio.NewWriter(w)
+	switch opts.format {
+	case renderANSI:
+		renderToANSI(bw, puzzle, h, opts)
+	case renderHTML:
+		renderToHTML(bw, puzzle, h, opts)
+	default:
+		return fmt.Errorf("unknown render format %q, expected %s or %s", opts.format, renderANSI, renderHTML)
+	}
+	return bw.Flush()
+}
+
+// renderToANSI draws the grid for a terminal
+func renderToANSI(w *bufio.Writer, puzzle [][]cell, h heat, opts renderOptions) {
+	maxCount := h.max(opts.layers)
+	for y, row := range puzzle {
+		for x, c := range row {
+			p1, p2 := h.counts(x, y, opts.layers)
+
+			var style string
+			switch {
+			case opts.heatmap && p1+p2 > 0:
+				style = fmt.Sprintf("%s\x1b[48;5;%dm", ansiHeatFg, heatANSI[heatIndex(p1+p2, maxCount)])
+			case opts.heatmap, p1+p2 == 0:
+				style = ansiDim
+			case p1 > 0 && p2 > 0:
+				style = ansiBoth
+			case p1 > 0:
+				style = ansiPart1
+			default:
+				style = ansiPart2
+			}
+			fmt.Fprintf(w, "%s%c%s", style, c.val, ansiReset)
+		}
+		w.WriteByte('\n')
+	}
+
+	// Legend
+	if opts.heatmap {
+		fmt.Fprintf(w, "matches per cell: 1 ")
+		for _, colour := range heatANSI {
+			fmt.Fprintf(w, "\x1b[48;5;%dm %s", colour, ansiReset)
+		}
+		fmt.Fprintf(w, " %d\n", maxCount)
+		return
+	}
+	fmt.Fprintf(w, "%sXMAS%s %sX-MAS%s %sboth%s\n", ansiPart1, ansiReset, ansiPart2, ansiReset, ansiBoth, ansiReset)
+}
+
+// renderToHTML draws the grid as a standalone HTML page
+func renderToHTML(w *bufio.Writer, puzzle [][]cell, h heat, opts renderOptions) {
+	maxCount := h.max(opts.layers)
+
+	w.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Advent of Code 2024 - Day 4</title>
+<style>
+body { background: #0f0f23; color: #cccccc; }
+pre { font-family: monospace; font-size: 14px; line-height: 1; }
+.dim { color: #444455; }
+.part1 { color: #ffff66; font-weight: bold; }
+.part2 { color: #00cccc; font-weight: bold; }
+.both { color: #ff66ff; font-weight: bold; }
+.heat { color: #000000; font-weight: bold; }
+</style>
+</head>
+<body>
+<pre>
+`)
+	for y, row := range puzzle {
+		for x, c := range row {
+			p1, p2 := h.counts(x, y, opts.layers)
+
+			class, style := "dim", ""
+			switch {
+			case opts.heatmap && p1+p2 > 0:
+				class = "heat"
+				style = fmt.Sprintf(` style="background: %s"`, heatRGB[heatIndex(p1+p2, maxCount)])
+			case opts.heatmap, p1+p2 == 0:
+			case p1 > 0 && p2 > 0:
+				class = "both"
+			case p1 > 0:
+				class = "part1"
+			default:
+				class = "part2"
+			}
+			fmt.Fprintf(w, `<span class="%s"%s title="(%d,%d) XMAS: %d, X-MAS: %d">%s</span>`,
+				class, style, x, y, p1, p2, html.EscapeString(string(c.val)))
+		}
+		w.WriteByte('\n')
+	}
+	w.WriteString("</pre>\n")
+
+	// Legend
+	if opts.heatmap {
+		fmt.Fprintf(w, "<p>matches per cell: 1 ")
+		for _, colour := range heatRGB {
+			fmt.Fprintf(w, `<span style="background: %s">&nbsp;</span>`, colour)
+		}
+		fmt.Fprintf(w, " %d</p>\n", maxCount)
+	} else {
+		w.WriteString(`<p><span class="part1">XMAS</span> <span class="part2">X-MAS</span> <span class="both">both</span></p>` + "\n")
+	}
+	w.WriteString("</body>\n</html>\n")
+}
+
+// counts returns the number of matches of each visible layer a cell participates in
+func (h heat) counts(x, y int, layers map[string]bool) (int, int) {
+	var p1, p2 int
+	if layers[layerPart1] {
+		p1 = h.part1[y][x]
+	}
+	if layers[layerPart2] {
+		p2 = h.part2[y][x]
+	}
+	return p1, p2
+}
+
+// max returns the highest number of visible matches any cell participates in
+func (h heat) max(layers map[string]bool) int {
+	var m int
+	for y, row := range h.part1 {
+		for x := range row {
+			p1, p2 := h.counts(x, y, layers)
+			m = max(m, p1+p2)
+		}
+	}
+	return m
+}
+
+// heatIndex scales a match count between 1 and maxCount onto the heatmap colours
+func heatIndex(count, maxCount int) int {
+	if maxCount <= 1 {
+		return len(heatANSI) - 1
+	}
+	return (count - 1) * (len(heatANSI) - 1) / (maxCount - 1)
+}
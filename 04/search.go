@@ -0,0 +1,245 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// match is a single occurrence of a dictionary word in the grid
+type match struct {
+	word  string    // The word that was found
+	start cell      // Cell holding the first letter of the word
+	dir   direction // Direction the word reads in from the start cell
+}
+
+// wordSearch finds every occurrence of a dictionary of words in a grid
+//
+// The dictionary is compiled into an Aho-Corasick automaton with a dense
+// transition table, so each line through the grid is scanned exactly once per
+// direction no matter how many words there are or how they overlap. Scanning
+// needs a byte per cell of the grid for its symbols, see BenchmarkWordSearch.
+type wordSearch struct {
+	words    []string       // Dictionary, deduplicated and sorted
+	lengths  []int          // Length of each word in runes
+	symbols  map[rune]int32 // Rune to symbol, 0 for runes in no word
+	ascii    [128]int32     // Same as symbols, for the common case of ASCII grids
+	alphabet int            // Number of symbols
+	next     []int32        // next[state*alphabet+symbol] is the following state
+	out      [][]int        // Indices of the words that end in each state
+}
+
+// newWordSearch compiles a dictionary of words into a word search engine
+func newWordSearch(words []string) *wordSearch {
+	ws := &wordSearch{symbols: make(map[rune]int32)}
+
+	// Deduplicate the dictionary so each occurrence is reported once
+	seen := make(map[string]bool)
+	for _, w := range words {
+		if w == "" || seen[w] {
+			continue
+		}
+		seen[w] = true
+		ws.words = append(ws.words, w)
+	}
+	sort.Strings(ws.words)
+
+	// Symbol 0 is reserved for runes that don't appear in any word
+	for _, w := range ws.words {
+		for _, r := range w {
+			if _, ok := ws.symbols[r]; !ok {
+				ws.symbols[r] = int32(len(ws.symbols) + 1)
+				if r < 128 {
+					ws.ascii[r] = ws.symbols[r]
+				}
+			}
+		}
+	}
+	ws.alphabet = len(ws.symbols) + 1
+
+	// Build the trie, trie[state][symbol] is the child state or 0 if there is none
+	trie := [][]int32{make([]int32, ws.alphabet)}
+	ws.out = [][]int{nil}
+	for i, w := range ws.words {
+		var state int32
+		var length int
+		for _, r := range w {
+			s := ws.symbols[r]
+			if trie[state][s] == 0 {
+				trie = append(trie, make([]int32, ws.alphabet))
+				ws.out = append(ws.out, nil)
+				trie[state][s] = int32(len(trie) - 1)
+			}
+			state = trie[state][s]
+			length++
+		}
+		ws.out[state] = append(ws.out[state], i)
+		ws.lengths = append(ws.lengths, length)
+	}
+
+	// Breadth-first, compute failure links and turn the trie into a complete automaton
+	ws.next = make([]int32, len(trie)*ws.alphabet)
+	fail := make([]int32, len(trie))
+	queue := make([]int32, 0, len(trie))
+	for s := 1; s < ws.alphabet; s++ {
+		if child := trie[0][s]; child != 0 {
+			ws.next[s] = child
+			queue = append(queue, child)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		// Words ending in the longest proper suffix also end here
+		ws.out[state] = append(ws.out[state], ws.out[fail[state]]...)
+
+		for s := 1; s < ws.alphabet; s++ {
+			fallback := ws.next[int(fail[state])*ws.alphabet+s]
+			if child := trie[state][s]; child != 0 {
+				ws.next[int(state)*ws.alphabet+s] = child
+				fail[child] = fallback
+				queue = append(queue, child)
+				continue
+			}
+			ws.next[int(state)*ws.alphabet+s] = fallback
+		}
+	}
+	return ws
+}
+
+// find returns every occurrence of a dictionary word reading in one of the given directions
+func (ws *wordSearch) find(puzzle [][]cell, dirs []direction) []match {
+	var matches []match
+	ws.scan(puzzle, dirs, func(m match) {
+		matches = append(matches, m)
+	})
+	return matches
+}
+
+// count returns the number of occurrences of dictionary words reading in one of the given directions
+func (ws *wordSearch) count(puzzle [][]cell, dirs []direction) int {
+	var n int
+	ws.scan(puzzle, dirs, func(match) {
+		n++
+	})
+	return n
+}
+
+// symbolGrid is the type the grid is translated into, as narrow as the alphabet allows
+type symbolGrid interface {
+	~uint8 | ~int32
+}
+
+// scan calls fn with every occurrence of a dictionary word reading in one of the given directions
+func (ws *wordSearch) scan(puzzle [][]cell, dirs []direction, fn func(match)) {
+	// A dictionary has few distinct runes, so one byte per cell is nearly always enough
+	if ws.alphabet <= math.MaxUint8+1 {
+		scanGrid[uint8](ws, puzzle, dirs, fn)
+		return
+	}
+	scanGrid[int32](ws, puzzle, dirs, fn)
+}
+
+// scanGrid translates the grid into automaton symbols of type S, then scans it in each direction
+func scanGrid[S symbolGrid](ws *wordSearch, puzzle [][]cell, dirs []direction, fn func(match)) {
+	// Translate the grid once, padding short rows with symbol 0 so that no
+	// word can run across a missing cell
+	var width int
+	for _, row := range puzzle {
+		width = max(width, len(row))
+	}
+	height := len(puzzle)
+	syms := make([]S, width*height)
+	for y, row := range puzzle {
+		for x, c := range row {
+			syms[y*width+x] = S(ws.symbol(c.val))
+		}
+	}
+
+	for _, dir := range dirs {
+		if dir.dx == 0 && dir.dy == 0 {
+			continue
+		}
+		scanDirection(ws, puzzle, syms, width, height, dir, fn)
+	}
+}
+
+// scanDirection runs the automaton along every line through the grid heading in dir
+//
+// Rather than walking each line to the end, which jumps a whole row of memory
+// per step for anything but horizontal lines, the grid is swept row by row
+// and the automaton state of every line is carried over from the row it came
+// from. Only the states of the last |dy| rows are kept.
+func scanDirection[S symbolGrid](ws *wordSearch, puzzle [][]cell, syms []S, width, height int, dir direction, fn func(match)) {
+	rows := abs(dir.dy) + 1
+	states := make([]int32, rows*width)
+
+	// Visit each cell after the cell before it on its line
+	y0, y1, ys := 0, height, 1
+	if dir.dy < 0 {
+		y0, y1, ys = height-1, -1, -1
+	}
+	x0, x1, xs := 0, width, 1
+	if dir.dx < 0 {
+		x0, x1, xs = width-1, -1, -1
+	}
+
+	for y := y0; y != y1; y += ys {
+		curr := states[(y%rows)*width:][:width]
+		var prev []int32 // Nil if the previous row of every line is off the grid
+		if py := y - dir.dy; py >= 0 && py < height {
+			prev = states[(py%rows)*width:][:width]
+		}
+
+		for x := x0; x != x1; x += xs {
+			var state int32
+			if px := x - dir.dx; prev != nil && px >= 0 && px < width {
+				state = prev[px]
+			}
+			state = ws.next[int(state)*ws.alphabet+int(syms[y*width+x])]
+			curr[x] = state
+
+			for _, w := range ws.out[state] {
+				// The word ends here, walk back to where it starts
+				back := ws.lengths[w] - 1
+				fn(match{
+					word:  ws.words[w],
+					start: puzzle[y-back*dir.dy][x-back*dir.dx],
+					dir:   dir,
+				})
+			}
+		}
+	}
+}
+
+// symbol maps a grid rune to its automaton symbol
+func (ws *wordSearch) symbol(r rune) int32 {
+	if r >= 0 && r < 128 {
+		return ws.ascii[r]
+	}
+	return ws.symbols[r]
+}
+
+// path returns the cells spelling out the match
+func (m match) path(puzzle [][]cell) []cell {
+	path := make([]cell, 0, len(m.word))
+	x, y := m.start.x, m.start.y
+	for range m.word {
+		path = append(path, puzzle[y][x])
+		x, y = x+m.dir.dx, y+m.dir.dy
+	}
+	return path
+}
+
+// abs returns the absolute value of an integer
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// inBounds checks if a cell is within the grid's boundaries
+func inBounds(x, y int, puzzle [][]cell) bool {
+	return y >= 0 && y < len(puzzle) && x >= 0 && x < len(puzzle[y])
+}
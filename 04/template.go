@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+// wildcardRune matches any cell in a template
+const wildcardRune rune = '.'
+
+// template is a 2D pattern of runes to match against the grid, '.' matches anything
+type template struct {
+	rows []string
+}
+
+// placement is a single position where a template matches the grid
+type placement struct {
+	variant int // Index of the template variant that matched
+	x, y    int // Grid position of the template's top-left corner
+}
+
+// templateCell is a non-wildcard cell of a template, relative to its top-left corner
+type templateCell struct {
+	dx, dy int
+	val    rune
+}
+
+// templateMatcher finds placements of a set of template variants in a grid
+type templateMatcher struct {
+	variants []template
+	cells    [][]templateCell // Cells each variant needs to match
+	width    []int            // Width of each variant
+	height   []int            // Height of each variant
+}
+
+// X-MAS cross used for part 2: two "MAS" on the diagonals of a 3x3 square
+var xmasTemplate = template{rows: []string{
+	"M.S",
+	".A.",
+	"M.S",
+}}
+
+// newTemplateMatcher creates a matcher for the given templates
+// If rotations is set all four rotations of each template are matched, and
+// if reflections is set the mirror images too. Variants that turn out
+// identical are only matched once, so symmetrical templates aren't double
+// counted.
+func newTemplateMatcher(templates []template, rotations, reflections bool) *templateMatcher {
+	tm := &templateMatcher{}
+	seen := make(map[string]bool)
+
+	add := func(t template) {
+		var key string
+		for _, row := range t.grid() {
+			key += string(row) + "\n"
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		tm.variants = append(tm.variants, t)
+	}
+
+	for _, t := range templates {
+		bases := []template{t}
+		if reflections {
+			bases = append(bases, t.reflect())
+		}
+		for _, b := range bases {
+			add(b)
+			if rotations {
+				for i, r := 0, b; i < 3; i++ {
+					r = r.rotate()
+					add(r)
+				}
+			}
+		}
+	}
+
+	for _, v := range tm.variants {
+		var cells []templateCell
+		var width int
+		for dy, row := range v.rows {
+			runes := []rune(row)
+			width = max(width, len(runes))
+			for dx, r := range runes {
+				if r != wildcardRune {
+					cells = append(cells, templateCell{dx: dx, dy: dy, val: r})
+				}
+			}
+		}
+		tm.cells = append(tm.cells, cells)
+		tm.width = append(tm.width, width)
+		tm.height = append(tm.height, len(v.rows))
+	}
+	return tm
+}
+
+// find returns every placement of every template variant in the grid
+func (tm *templateMatcher) find(puzzle [][]cell) []placement {
+	var placements []placement
+	tm.scan(puzzle, func(p placement) {
+		placements = append(placements, p)
+	})
+	return placements
+}
+
+// count returns the number of placements of template variants in the grid
+func (tm *templateMatcher) count(puzzle [][]cell) int {
+	var n int
+	tm.scan(puzzle, func(placement) {
+		n++
+	})
+	return n
+}
+
+// scan calls fn with every placement of every template variant in the grid
+func (tm *templateMatcher) scan(puzzle [][]cell, fn func(placement)) {
+	for v, cells := range tm.cells {
+		for y := 0; y+tm.height[v] <= len(puzzle); y++ {
+			for x := 0; x+tm.width[v] <= len(puzzle[y]); x++ {
+				if tm.matchesAt(puzzle, cells, x, y) {
+					fn(placement{variant: v, x: x, y: y})
+				}
+			}
+		}
+	}
+}
+
+// matchesAt checks whether every cell of a template matches the grid with its top-left corner at (x, y)
+func (tm *templateMatcher) matchesAt(puzzle [][]cell, cells []templateCell, x, y int) bool {
+	for _, c := range cells {
+		nx, ny := x+c.dx, y+c.dy
+		if !inBounds(nx, ny, puzzle) || puzzle[ny][nx].val != c.val {
+			return false
+		}
+	}
+	return true
+}
+
+// placementCells returns the grid cells covered by the non-wildcard cells of a placement
+func (tm *templateMatcher) placementCells(puzzle [][]cell, p placement) []cell {
+	cells := make([]cell, 0, len(tm.cells[p.variant]))
+	for _, c := range tm.cells[p.variant] {
+		cells = append(cells, puzzle[p.y+c.dy][p.x+c.dx])
+	}
+	return cells
+}
+
+// rotate returns the template rotated 90 degrees clockwise
+func (t template) rotate() template {
+	grid := t.grid()
+	height := len(grid)
+	var width int
+	if height > 0 {
+		width = len(grid[0])
+	}
+
+	rows := make([]string, width)
+	for x := 0; x < width; x++ {
+		row := make([]rune, height)
+		for y := 0; y < height; y++ {
+			row[y] = grid[height-1-y][x]
+		}
+		rows[x] = string(row)
+	}
+	return template{rows: rows}
+}
+
+// reflect returns the template mirrored left to right
+func (t template) reflect() template {
+	grid := t.grid()
+	rows := make([]string, len(grid))
+	for y, row := range grid {
+		mirrored := make([]rune, len(row))
+		for x, r := range row {
+			mirrored[len(row)-1-x] = r
+		}
+		rows[y] = string(mirrored)
+	}
+	return template{rows: rows}
+}
+
+// grid returns the template as a rectangle of runes, padding short rows with wildcards
+func (t template) grid() [][]rune {
+	var width int
+	for _, row := range t.rows {
+		width = max(width, len([]rune(row)))
+	}
+
+	grid := make([][]rune, len(t.rows))
+	for y, row := range t.rows {
+		grid[y] = []rune(row)
+		for len(grid[y]) < width {
+			grid[y] = append(grid[y], wildcardRune)
+		}
+	}
+	return grid
+}
+
+// readTemplates reads templates from a file, templates are separated by blank lines
+func readTemplates(fname string) ([]template, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file [%s]: %w", fname, err)
+	}
+	defer f.Close()
+
+	templates, err := parseTemplates(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
+	}
+	return templates, nil
+}
+
+// parseTemplates parses templates separated by blank lines
+func parseTemplates(r io.Reader) ([]template, error) {
+	var templates []template
+
+	scanner := bufio.NewScanner(input.Normalize(r))
+	var t template
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		// A blank line ends the current template
+		if line == "" {
+			if len(t.rows) > 0 {
+				templates = append(templates, t)
+				t = template{}
+			}
+			continue
+		}
+		t.rows = append(t.rows, line)
+	}
+	if len(t.rows) > 0 {
+		templates = append(templates, t)
+	}
+
+	// Check for errors in reading the templates
+	if err := scanner.Err(); err != nil {
+		return nil, &input.ParseError{Line: lineNum + 1, Err: fmt.Errorf("error reading templates: %w", err)}
+	}
+	if len(templates) == 0 {
+		return nil, &input.ParseError{Err: errors.New("no templates found")}
+	}
+
+	return templates, nil
+}
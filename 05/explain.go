@@ -0,0 +1,166 @@
+package main
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+// A single page move that helps turn an update list into its corrected order
+// Applying the moves in order, each page goes right after the page named in after
+type pageMove struct {
+	page  int // Page to move
+	to    int // Position in the corrected order
+	after int // Page it goes right after, or -1 if it goes first
+}
+
+// Why an update list is out of order and how to fix it
+type explanation struct {
+	violated [][]int    // Every rule [A,B] broken because B is printed before A
+	kept     []int      // Pages that can stay where they are
+	moves    []pageMove // Fewest pages that need to move
+}
+
+// Returns the update list sorted so it follows all applicable rules, using Kahn's algorithm
+func calcCorrectOrder(updateList []int, applicableRules [][]int) ([]int, error) {
+	dependencyCount := calcDependencyCount(applicableRules)
+	pageToDepsMap := calcPageToDepsMap(applicableRules)
+	zeroRulesPages := initZeroRulesPagesQueue(dependencyCount, updateList)
+
+	order := make([]int, 0, len(updateList))
+	for zeroRulesPages.Len() > 0 {
+		front := zeroRulesPages.Front()
+		zeroRulesPages.Remove(front)
+		page := front.Value.(int)
+		order = append(order, page)
+
+		// Pages whose dependencies are now all printed can go next
+		for _, dep := range pageToDepsMap[page] {
+			dependencyCount[dep]--
+			if dependencyCount[dep] == 0 {
+				zeroRulesPages.PushBack(dep)
+			}
+		}
+	}
+
+	// Pages left over depend on each other in a cycle
+	if len(order) != len(updateList) {
+		return nil, errors.New("rules applicable to the update list contain a cycle")
+	}
+	return order, nil
+}
+
+// Returns a map of page to the number of dependencies (in-degree)
+func calcDependencyCount(rules [][]int) map[int]int {
+	dc := make(map[int]int)
+	for _, rule := range rules {
+		page := rule[1]
+		dc[page]++ // Increment dependency count for the dependent page
+	}
+	return dc
+}
+
+// Initializes a queue with pages that have zero dependencies
+func initZeroRulesPagesQueue(dependencyCount map[int]int, updateList []int) *list.List {
+	zeroRulesPages := list.New()
+	for _, page := range updateList {
+		if dependencyCount[page] == 0 {
+			zeroRulesPages.PushBack(page)
+		}
+	}
+	return zeroRulesPages
+}
+
+// Explains why an update list is out of order given its corrected order
+//
+// Every broken rule is listed rather than a smallest subset of them: each is a
+// pair of pages printed the wrong way round, and any corrected order has to
+// fix all of them. It's the page moves that are kept to a minimum.
+//
+// The pages that can stay put are the longest subsequence of the update list
+// that is already in corrected order; every other page has to move. When the
+// rules order every pair of pages in the list, as the puzzle input does, this
+// is the fewest moves possible.
+func explainUpdate(updateList []int, applicableRules [][]int, correctOrder []int) explanation {
+	position := make(map[int]int)
+	for i, page := range correctOrder {
+		position[page] = i
+	}
+
+	// Longest increasing subsequence of corrected positions, in O(n log n)
+	tails := make([]int, 0)              // tails[k] is the index of the smallest tail of a subsequence of length k+1
+	prev := make([]int, len(updateList)) // Previous index in the subsequence ending at each index
+	for i, page := range updateList {
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if position[updateList[tails[mid]]] < position[page] {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		prev[i] = -1
+		if lo > 0 {
+			prev[i] = tails[lo-1]
+		}
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+
+	stays := make(map[int]bool)
+	kept := make([]int, len(tails))
+	if len(tails) > 0 {
+		for i, k := tails[len(tails)-1], len(tails)-1; i != -1; i, k = prev[i], k-1 {
+			kept[k] = updateList[i]
+			stays[updateList[i]] = true
+		}
+	}
+
+	// Every other page moves to its corrected position
+	moves := make([]pageMove, 0)
+	for i, page := range correctOrder {
+		if stays[page] {
+			continue
+		}
+		move := pageMove{page: page, to: i, after: -1}
+		if i > 0 {
+			move.after = correctOrder[i-1]
+		}
+		moves = append(moves, move)
+	}
+
+	return explanation{
+		violated: calcViolatedRules(applicableRules, updateList),
+		kept:     kept,
+		moves:    moves,
+	}
+}
+
+// Formats an explanation as indented lines of text
+func (e explanation) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "\tbroken rules (%d):", len(e.violated))
+	for _, rule := range e.violated {
+		fmt.Fprintf(&sb, " %d|%d", rule[0], rule[1])
+	}
+	sb.WriteString("\n")
+
+	fmt.Fprintf(&sb, "\tpages that stay (%d): %s\n", len(e.kept), input.JoinInts(e.kept, ","))
+	fmt.Fprintf(&sb, "\tpage moves (%d):\n", len(e.moves))
+	for _, m := range e.moves {
+		if m.after == -1 {
+			fmt.Fprintf(&sb, "\t\tmove %d to position %d (first)\n", m.page, m.to)
+			continue
+		}
+		fmt.Fprintf(&sb, "\t\tmove %d to position %d (after %d)\n", m.page, m.to, m.after)
+	}
+	return sb.String()
+}
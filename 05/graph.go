@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+// Colours used in the DOT export
+const (
+	dotCycleColour     string = "orange" // Rule that is part of a cycle in the global rule set
+	dotViolationColour string = "red"    // Rule broken by an update
+	dotValidColour     string = "darkgreen"
+)
+
+// Returns the sorted list of pages mentioned by the rules
+func rulePages(rules [][]int) []int {
+	seen := make(map[int]bool)
+	pages := make([]int, 0)
+	for _, rule := range rules {
+		for _, page := range rule {
+			if !seen[page] {
+				seen[page] = true
+				pages = append(pages, page)
+			}
+		}
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+// Returns the strongly connected components of the rule graph using Tarjan's algorithm
+// Components are returned in reverse topological order, each sorted by page
+func calcSCCs(pages []int, pageToDepsMap map[int][]int) [][]int {
+	index := make(map[int]int)   // Order in which each page was first visited
+	lowLink := make(map[int]int) // Smallest index reachable from each page
+	onStack := make(map[int]bool)
+	stack := make([]int, 0)
+	sccs := make([][]int, 0)
+	next := 0
+
+	var visit func(page int)
+	visit = func(page int) {
+		index[page] = next
+		lowLink[page] = next
+		next++
+		stack = append(stack, page)
+		onStack[page] = true
+
+		for _, dep := range pageToDepsMap[page] {
+			if _, seen := index[dep]; !seen {
+				visit(dep)
+				lowLink[page] = min(lowLink[page], lowLink[dep])
+			} else if onStack[dep] {
+				lowLink[page] = min(lowLink[page], index[dep])
+			}
+		}
+
+		// page is the root of a component, pop it off the stack
+		if lowLink[page] == index[page] {
+			scc := make([]int, 0)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				scc = append(scc, top)
+				if top == page {
+					break
+				}
+			}
+			sort.Ints(scc)
+			sccs = append(sccs, scc)
+		}
+	}
+
+	for _, page := range pages {
+		if _, seen := index[page]; !seen {
+			visit(page)
+		}
+	}
+	return sccs
+}
+
+// Returns the components that contain a cycle: more than one page, or a page that must come before itself
+func cyclicSCCs(sccs [][]int, pageToDepsMap map[int][]int) [][]int {
+	cyclic := make([][]int, 0)
+	for _, scc := range sccs {
+		if len(scc) > 1 || indexOf(pageToDepsMap[scc[0]], scc[0]) != -1 {
+			cyclic = append(cyclic, scc)
+		}
+	}
+	return cyclic
+}
+
+// Returns the rules that the update list breaks, i.e. [A,B] where B is printed before A
+func calcViolatedRules(applicableRules [][]int, updateList []int) [][]int {
+	position := make(map[int]int)
+	for i, page := range updateList {
+		position[page] = i
+	}
+
+	violated := make([][]int, 0)
+	for _, rule := range applicableRules {
+		if position[rule[0]] > position[rule[1]] {
+			violated = append(violated, rule)
+		}
+	}
+	return violated
+}
+
+// Writes the global rule graph and the subgraph of every update list as Graphviz DOT
+// Edges inside a cycle of the global rule set are highlighted, as are the rules each invalid update breaks
+func writeDOT(w io.Writer, rules [][]int, updateLists [][]int) error {
+	bw := bufio.NewWriter(w)
+
+	pages := rulePages(rules)
+	pageToDepsMap := calcPageToDepsMap(rules)
+	sccs := calcSCCs(pages, pageToDepsMap)
+	cyclic := cyclicSCCs(sccs, pageToDepsMap)
+
+	// Map each page to its component so edges within a cycle can be found
+	sccOf := make(map[int]int)
+	for i, scc := range sccs {
+		for _, page := range scc {
+			sccOf[page] = i
+		}
+	}
+
+	// Global rule graph
+	fmt.Fprintf(bw, "// %d pages, %d rules, %d strongly connected components, %d cyclic\n", len(pages), len(rules), len(sccs), len(cyclic))
+	for _, scc := range cyclic {
+		fmt.Fprintf(bw, "// cycle: %s\n", input.JoinInts(scc, " "))
+	}
+	fmt.Fprintln(bw, "digraph rules {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	fmt.Fprintln(bw, "\tnode [shape=circle];")
+	for i, scc := range sccs {
+		if len(scc) > 1 {
+			fmt.Fprintf(bw, "\tsubgraph cluster_scc_%d {\n\t\tlabel=\"scc %d\";\n\t\tcolor=%s;\n", i, i, dotCycleColour)
+			for _, page := range scc {
+				fmt.Fprintf(bw, "\t\t%d;\n", page)
+			}
+			fmt.Fprintln(bw, "\t}")
+			continue
+		}
+		fmt.Fprintf(bw, "\t%d;\n", scc[0])
+	}
+	for _, rule := range rules {
+		attrs := ""
+		if sccOf[rule[0]] == sccOf[rule[1]] {
+			attrs = fmt.Sprintf(" [color=%s]", dotCycleColour)
+		}
+		fmt.Fprintf(bw, "\t%d -> %d%s;\n", rule[0], rule[1], attrs)
+	}
+	fmt.Fprintln(bw, "}")
+
+	// Subgraph of each update list
+	for i, updateList := range updateLists {
+		applicableRules := calcApplicableRules(rules, updateList)
+		violated := calcViolatedRules(applicableRules, updateList)
+
+		status, colour := "valid", dotValidColour
+		if len(violated) > 0 {
+			status, colour = fmt.Sprintf("invalid, %d rules broken", len(violated)), dotViolationColour
+		}
+
+		fmt.Fprintf(bw, "\ndigraph update_%d {\n", i)
+		fmt.Fprintf(bw, "\tlabel=\"update %d: %s (%s)\";\n", i, input.JoinInts(updateList, ","), status)
+		fmt.Fprintf(bw, "\tfontcolor=%s;\n", colour)
+		fmt.Fprintln(bw, "\trankdir=LR;")
+		fmt.Fprintln(bw, "\tnode [shape=circle];")
+		for pos, page := range updateList {
+			fmt.Fprintf(bw, "\t%d [xlabel=\"#%d\"];\n", page, pos)
+		}
+		for _, rule := range applicableRules {
+			attrs := ""
+			if indexOfRule(violated, rule) != -1 {
+				attrs = fmt.Sprintf(" [color=%s, penwidth=2]", dotViolationColour)
+			}
+			fmt.Fprintf(bw, "\t%d -> %d%s;\n", rule[0], rule[1], attrs)
+		}
+		fmt.Fprintln(bw, "}")
+	}
+
+	return bw.Flush()
+}
+
+// Finds the index of a rule in a list of rules
+func indexOfRule(rules [][]int, rule []int) int {
+	for i, r := range rules {
+		if r[0] == rule[0] && r[1] == rule[1] {
+			return i
+		}
+	}
+	return -1
+}
@@ -1,71 +1,128 @@
 package main
 
 import (
-	"bufio"
-	"container/list"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"strconv"
-	"strings"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 5: Challenge
 // https://adventofcode.com/2024/day/5
 
 const (
-	fileName   string = "easy-example.txt"
+	fileName   string = "example.txt"
 	delimPipe  string = "|"
 	delimComma string = ","
 )
 
 func main() {
+	graph := flag.Bool("graph", false, "export the rule graph and each update's subgraph as Graphviz DOT")
+	graphOut := flag.String("graph-out", "", "file to write the DOT export to, defaults to stdout")
+	explain := flag.Bool("explain", false, "write the corrected order of each invalid update list and the moves that fix it to stderr")
+	run := result.Main(5, fileName)
+
 	// Read input rules and update lists from the file
 	rules, updateLists, err := readInput(fileName)
 	if err != nil {
-		fmt.Println("error reading input: %w", err)
-		os.Exit(1)
+		run.Fatal(err)
+	}
+
+	// Export the graphs instead of validating
+	if *graph {
+		if err := exportGraph(*graphOut, rules, updateLists); err != nil {
+			slog.Error("exporting graph", "err", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Iterate over each update list
+	// Part 1: Sum the middle pages of the valid update lists
+	run.Part(1, func() (any, error) {
+		return sumValid(rules, updateLists), nil
+	})
+
+	// Part 2: Put the invalid update lists in order, sum their middle pages and explain what was wrong
+	var explainOut io.Writer // Explanations go to stderr so they don't mix with the results
+	if *explain {
+		explainOut = os.Stderr
+	}
+	run.Part(2, func() (any, error) {
+		return sumCorrected(rules, updateLists, explainOut)
+	})
+
+	run.Finish()
+}
+
+// Sums the middle pages of the update lists that already follow every applicable rule
+func sumValid(rules [][]int, updateLists [][]int) int {
+	var sum int
 	for _, updateList := range updateLists {
-		fmt.Println("---------------------")
 		// Filter rules to those relevant for the current update list
 		applicableRules := calcApplicableRules(rules, updateList)
-		fmt.Println("applicableRules")
-		fmt.Println(applicableRules)
-
-		// Create a dependency count map (page: number of dependencies)
-		dependencyCount := calcDependencyCount(applicableRules)
-		fmt.Println("dependencyCount")
-		fmt.Println(dependencyCount)
+		violated := calcViolatedRules(applicableRules, updateList)
+		slog.Debug("update list",
+			"pages", input.JoinInts(updateList, delimComma),
+			"applicableRules", applicableRules,
+			"violatedRules", violated)
+
+		if len(violated) == 0 {
+			sum += updateList[len(updateList)/2]
+		}
+	}
+	return sum
+}
 
-		// Create an adjacency list (page: dependencies)
-		pageToDepsMap := calcPageToDepsMap(applicableRules)
-		fmt.Println("pageToDepsMap")
-		fmt.Println(pageToDepsMap)
+// Puts the update lists that break a rule in order and sums their middle pages
+// If explain is non-nil the corrected order of each and the moves that fix it are written to it
+func sumCorrected(rules [][]int, updateLists [][]int, explain io.Writer) (int, error) {
+	var sum int
+	for _, updateList := range updateLists {
+		applicableRules := calcApplicableRules(rules, updateList)
+		if len(calcViolatedRules(applicableRules, updateList)) == 0 {
+			continue
+		}
+		correctOrder, err := calcCorrectOrder(updateList, applicableRules)
+		if err != nil {
+			return 0, fmt.Errorf("error correcting update list [%s]: %w", input.JoinInts(updateList, delimComma), err)
+		}
+		sum += correctOrder[len(correctOrder)/2]
+		if explain != nil {
+			fmt.Fprintf(explain, "corrected order: %s\n", input.JoinInts(correctOrder, delimComma))
+			fmt.Fprint(explain, explainUpdate(updateList, applicableRules, correctOrder))
+		}
+	}
+	return sum, nil
+}
 
-		// Initialize a queue for pages with zero dependencies
-		zeroRulesPages := initZeroRulesPagesQueue(dependencyCount, updateList)
-		fmt.Println("zeroRulesPages")
-		printQueue(zeroRulesPages)
+// Writes the DOT export to the named file, or stdout if fname is empty
+func exportGraph(fname string, rules [][]int, updateLists [][]int) error {
+	if fname == "" {
+		return writeDOT(os.Stdout, rules, updateLists)
+	}
 
-		// Validate the current update list against the rules
-		valid, middleNum := validateUpdateList(updateList, pageToDepsMap, dependencyCount, zeroRulesPages)
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("error creating file [%s]: %w", fname, err)
+	}
+	defer f.Close()
 
-		// Output the validation result and the middle number
-		fmt.Printf("valid: %t\n", valid)
-		fmt.Printf("middleNum: %d\n", middleNum)
+	if err := writeDOT(f, rules, updateLists); err != nil {
+		return fmt.Errorf("error writing file [%s]: %w", fname, err)
 	}
+	return f.Close()
 }
 
 // Reads the input file
+// The rules and the update lists are separated by a blank line
 // Outputs:
 // - rules: list of pairs where [A,B] A must come before B
 // - updateLists: list of lists which are the order to be updated
 func readInput(fname string) ([][]int, [][]int, error) {
-	rules := make([][]int, 0)
-	updateLists := make([][]int, 0)
-
 	// Open the input file
 	f, err := os.Open(fname)
 	if err != nil {
@@ -73,67 +130,50 @@ func readInput(fname string) ([][]int, [][]int, error) {
 	}
 	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Read page ordering rules (e.g., "A|B")
-		if strings.Contains(line, delimPipe) {
-			values := strings.Split(line, delimPipe)
-			if len(values) != 2 {
-				return nil, nil, fmt.Errorf("incorrect format, expected int|int, found %s", line)
-			}
-
-			page, err1 := strconv.Atoi(values[0])
-			rule, err2 := strconv.Atoi(values[1])
-			if err1 != nil || err2 != nil {
-				return nil, nil, fmt.Errorf("error parsing rules: %s", line)
-			}
-			rules = append(rules, []int{page, rule})
+	rules, updateLists, err := parseInput(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
+	}
+	return rules, updateLists, nil
+}
 
-		} else if strings.Contains(line, delimComma) {
-			// Read update list (e.g., "1,2,3")
-			updateList := strings.Split(line, delimComma)
-			intList, err := stringSliceToIntSlice(updateList)
-			if err != nil {
-				return nil, nil, err
-			}
-			updateLists = append(updateLists, intList)
-		}
+// Parses the rules and the update lists, separated by a blank line
+func parseInput(r io.Reader) ([][]int, [][]int, error) {
+	rules := input.NewSection("rules", parseRule)
+	updateLists := input.NewSection("updates", parseUpdateList)
+	if err := input.ReadSections(r, rules, updateLists); err != nil {
+		return nil, nil, err
 	}
+	return rules.Values, updateLists.Values, nil
+}
 
-	// Check for errors in reading the file
-	if err := scanner.Err(); err != nil {
-		return nil, nil, fmt.Errorf("error reading file [%s]: %w", fname, err)
+// Parses a page ordering rule (e.g., "A|B")
+func parseRule(line string) ([]int, error) {
+	values := input.Split(line, delimPipe)
+	if len(values) != 2 {
+		return nil, fmt.Errorf("incorrect format, expected int%sint", delimPipe)
 	}
+	return fieldsToInts(values)
+}
 
-	return rules, updateLists, nil
+// Parses an update list (e.g., "1,2,3")
+func parseUpdateList(line string) ([]int, error) {
+	return fieldsToInts(input.Split(line, delimComma))
 }
 
-// Converts a slice of strings to a slice of integers
-func stringSliceToIntSlice(ss []string) ([]int, error) {
-	export := make([]int, len(ss))
-	for i, s := range ss {
-		val, err := strconv.Atoi(s)
+// Converts the fields of a line to a slice of integers
+func fieldsToInts(fields []input.Field) ([]int, error) {
+	export := make([]int, len(fields))
+	for i, f := range fields {
+		val, err := f.Int()
 		if err != nil {
-			return nil, fmt.Errorf("error converting string to int: %s", s)
+			return nil, err
 		}
 		export[i] = val
 	}
 	return export, nil
 }
 
-// Returns a map of page to the number of dependencies (in-degree)
-func calcDependencyCount(rules [][]int) map[int]int {
-	dc := make(map[int]int)
-	for _, rule := range rules {
-		page := rule[1]
-		dc[page]++ // Increment dependency count for the dependent page
-	}
-	return dc
-}
-
 // Returns a map of page to its direct dependencies (adjacency list)
 func calcPageToDepsMap(rules [][]int) map[int][]int {
 	pageToDeps := make(map[int][]int)
@@ -166,104 +206,6 @@ func calcApplicableRules(rules [][]int, updateList []int) [][]int {
 	return applicableRules
 }
 
-// Initializes a queue with pages that have zero dependencies
-func initZeroRulesPagesQueue(dependencyCount map[int]int, updateList []int) *list.List {
-	zeroRulesPages := list.New()
-	for _, page := range updateList {
-		if dependencyCount[page] == 0 {
-			zeroRulesPages.PushBack(page)
-		}
-	}
-	return zeroRulesPages
-}
-
-// Updates the dependency count map based on the adjacency list
-func updateDepsMap(dependencyCount map[int]int, pageToDepsMap map[int][]int) map[int]int {
-	for page, deps := range pageToDepsMap {
-		dependencyCount[page] = len(deps)
-	}
-	return dependencyCount
-}
-
-// Validates the update list using Kahn's algorithm
-// Outputs:
-// - valid: whether the update list is valid
-// - middleNum: the middle page of the update order
-func validateUpdateList(updateList []int, pageToDepsMap map[int][]int, dependencyCount map[int]int, zeroRulesPages *list.List) (bool, int) {
-	fmt.Println("validateUpdateList()")
-	readOrder := make([]int, 0)
-
-	printQueue(zeroRulesPages)
-	// Process pages in topological order
-	for _, page := range updateList {
-		zeroRulesPage := zeroRulesPages.Front()
-		// for zeroRulesPage := zeroRulesPages.Front(); zeroRulesPage != nil; zeroRulesPage = zeroRulesPage.Next() {
-		// page := zeroRulesPage.Value.(int)
-		fmt.Println("PAGE")
-		fmt.Println(page)
-
-		// Append the current page to the read order
-		readOrder = append(readOrder, page)
-		// fmt.Println("readOrder")
-		// fmt.Println(readOrder)
-
-		zeroRulesPages.Remove(zeroRulesPage)
-		// fmt.Println("zeroRulesPages.Remove(zeroRulesPage)")
-		// printQueue(zeroRulesPages)
-
-		// Reduce the dependency count for all dependents of the current page
-		fmt.Println("dependencyCount[page]")
-		fmt.Println(dependencyCount[page])
-		dependencyCount[page]--
-		if dependencyCount[page] == 0 {
-			zeroRulesPages.PushBack(page)
-			for p, deps := range pageToDepsMap {
-				for i, dep := range deps {
-					if dep == page {
-						// remove the current page from the list of existing pages
-						copy := append([]int{}, remove(deps, i)...)
-						// add the new deps map back to the pageToDepsMap
-						pageToDepsMap[p] = copy
-					}
-				}
-			}
-			zeroRulesPages = initZeroRulesPagesQueue(dependencyCount, updateList)
-		}
-
-		// reset zerorulespages stuff
-		zeroRulesPage = zeroRulesPage.Next()
-		if zeroRulesPage == nil {
-			break
-		}
-
-	}
-	// If not all pages were processed, the update list is invalid
-	if len(readOrder) != len(updateList) {
-		return false, 0
-	}
-
-	// Return the middle page in the sorted order
-	return true, readOrder[len(readOrder)/2]
-}
-
-// Prints the elements of a queue
-func printQueue(l *list.List) {
-	for e := l.Front(); e != nil; e = e.Next() {
-		fmt.Printf("\t%d\n", e.Value.(int))
-	}
-}
-
-// Removes a specific value from a slice
-func removeVal(s []int, v int) []int {
-	i := indexOf(s, v)
-	return remove(s, i)
-}
-
-// Removes an element at a specific index from a slice
-func remove(s []int, i int) []int {
-	return append(s[:i], s[i+1:]...)
-}
-
 // Finds the index of a value in a slice
 func indexOf(s []int, v int) int {
 	for i := range s {
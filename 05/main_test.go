@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func FuzzParseInput(f *testing.F) {
+	f.Add("47|53\n97|13\n75|29\n\n75,47,61,53,29\n97,61,53,29,13\n")
+	f.Add("47|53|1\n\n1,2\n")
+	f.Add("47|53\n")
+	f.Add("47|53\n\n1,,2\n\n3\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		rules, updateLists, err := parseInput(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		for _, rule := range rules {
+			if len(rule) != 2 {
+				t.Fatalf("rule with %d pages", len(rule))
+			}
+		}
+		for _, updateList := range updateLists {
+			if len(updateList) == 0 {
+				t.Fatal("empty update list")
+			}
+		}
+	})
+}
+
+// Example from the puzzle
+const example = `47|53
+97|13
+97|61
+97|47
+75|29
+61|13
+75|53
+29|13
+97|29
+53|29
+61|53
+97|53
+61|29
+47|13
+75|47
+97|75
+47|61
+75|61
+47|29
+75|13
+53|13
+
+75,47,61,53,29
+97,61,53,29,13
+75,29,13
+75,97,47,61,53
+61,13,29
+97,13,75,29,47
+`
+
+// parse parses input for a test
+func parse(t *testing.T, text string) ([][]int, [][]int) {
+	t.Helper()
+	rules, updateLists, err := parseInput(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules, updateLists
+}
+
+func TestSCCs(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  [][]int
+		sccs   [][]int
+		cyclic [][]int
+	}{
+		{
+			name:   "chain",
+			rules:  [][]int{{1, 2}, {2, 3}},
+			sccs:   [][]int{{3}, {2}, {1}},
+			cyclic: [][]int{},
+		},
+		{
+			name:   "cycle with a tail",
+			rules:  [][]int{{1, 2}, {2, 3}, {3, 1}, {3, 4}},
+			sccs:   [][]int{{4}, {1, 2, 3}},
+			cyclic: [][]int{{1, 2, 3}},
+		},
+		{
+			name:   "two cycles",
+			rules:  [][]int{{1, 2}, {2, 1}, {2, 3}, {3, 4}, {4, 3}},
+			sccs:   [][]int{{3, 4}, {1, 2}},
+			cyclic: [][]int{{3, 4}, {1, 2}},
+		},
+		{
+			name:   "page before itself",
+			rules:  [][]int{{5, 5}, {5, 6}},
+			sccs:   [][]int{{6}, {5}},
+			cyclic: [][]int{{5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pageToDepsMap := calcPageToDepsMap(tt.rules)
+			sccs := calcSCCs(rulePages(tt.rules), pageToDepsMap)
+			if !reflect.DeepEqual(sccs, tt.sccs) {
+				t.Errorf("components: got %v, want %v", sccs, tt.sccs)
+			}
+			if cyclic := cyclicSCCs(sccs, pageToDepsMap); !reflect.DeepEqual(cyclic, tt.cyclic) {
+				t.Errorf("cyclic: got %v, want %v", cyclic, tt.cyclic)
+			}
+		})
+	}
+}
+
+func TestExampleRulesAreAcyclic(t *testing.T) {
+	rules, _ := parse(t, example)
+	pageToDepsMap := calcPageToDepsMap(rules)
+	sccs := calcSCCs(rulePages(rules), pageToDepsMap)
+	if cyclic := cyclicSCCs(sccs, pageToDepsMap); len(cyclic) != 0 {
+		t.Fatalf("got cycles %v", cyclic)
+	}
+	if len(sccs) != 7 {
+		t.Fatalf("got %d components, want one per page", len(sccs))
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var sb strings.Builder
+	rules := [][]int{{1, 2}, {2, 1}, {2, 3}}
+	if err := writeDOT(&sb, rules, [][]int{{1, 3}, {3, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	want := `// 3 pages, 3 rules, 2 strongly connected components, 1 cyclic
+// cycle: 1 2
+digraph rules {
+	rankdir=LR;
+	node [shape=circle];
+	3;
+	subgraph cluster_scc_1 {
+		label="scc 1";
+		color=orange;
+		1;
+		2;
+	}
+	1 -> 2 [color=orange];
+	2 -> 1 [color=orange];
+	2 -> 3;
+}
+
+digraph update_0 {
+	label="update 0: 1,3 (valid)";
+	fontcolor=darkgreen;
+	rankdir=LR;
+	node [shape=circle];
+	1 [xlabel="#0"];
+	3 [xlabel="#1"];
+}
+
+digraph update_1 {
+	label="update 1: 3,2 (invalid, 1 rules broken)";
+	fontcolor=red;
+	rankdir=LR;
+	node [shape=circle];
+	3 [xlabel="#0"];
+	2 [xlabel="#1"];
+	2 -> 3 [color=red, penwidth=2];
+}
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSumValidExample(t *testing.T) {
+	rules, updateLists := parse(t, example)
+	if got := sumValid(rules, updateLists); got != 143 {
+		t.Fatalf("got %d, want 143", got)
+	}
+}
+
+func TestCyclicRules(t *testing.T) {
+	// Every order of the update breaks one of the rules
+	rules, updateLists := parse(t, "1|2\n2|3\n3|1\n\n1,2,3\n")
+	if got := sumValid(rules, updateLists); got != 0 {
+		t.Fatalf("part 1: got %d, want 0", got)
+	}
+
+	_, err := calcCorrectOrder(updateLists[0], calcApplicableRules(rules, updateLists[0]))
+	if err == nil || !strings.Contains(err.Error(), "contain a cycle") {
+		t.Fatalf("got error %v, want a cycle", err)
+	}
+}
+
+func TestSumCorrectedExample(t *testing.T) {
+	rules, updateLists := parse(t, example)
+	var sb strings.Builder
+	got, err := sumCorrected(rules, updateLists, &sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 123 {
+		t.Errorf("got %d, want 123", got)
+	}
+
+	want := `corrected order: 97,75,47,61,53
+	broken rules (1): 97|75
+	pages that stay (4): 97,47,61,53
+	page moves (1):
+		move 75 to position 1 (after 97)
+corrected order: 61,29,13
+	broken rules (1): 29|13
+	pages that stay (2): 61,29
+	page moves (1):
+		move 13 to position 2 (after 29)
+corrected order: 97,75,47,29,13
+	broken rules (4): 29|13 47|13 47|29 75|13
+	pages that stay (3): 97,75,47
+	page moves (2):
+		move 29 to position 3 (after 47)
+		move 13 to position 4 (after 29)
+`
+	if sb.String() != want {
+		t.Errorf("explanation: got\n%s\nwant\n%s", sb.String(), want)
+	}
+
+	// Without a writer nothing is explained, the answer is the same
+	if got, err := sumCorrected(rules, updateLists, nil); err != nil || got != 123 {
+		t.Errorf("without explanations: got %d, %v, want 123", got, err)
+	}
+}
+
+func TestSumCorrectedCycle(t *testing.T) {
+	rules, updateLists := parse(t, "1|2\n2|3\n3|1\n\n1,2,3\n")
+	_, err := sumCorrected(rules, updateLists, nil)
+	if err == nil || !strings.Contains(err.Error(), "contain a cycle") {
+		t.Fatalf("got error %v, want a cycle", err)
+	}
+}
+
+func TestExplainUpdate(t *testing.T) {
+	// Every pair of pages is ordered, smaller pages first
+	order := [][]int{{1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}
+	tests := []struct {
+		name   string
+		update []int
+		kept   []int
+		moves  []pageMove
+	}{
+		{name: "in order", update: []int{1, 2, 3, 4}, kept: []int{1, 2, 3, 4}, moves: []pageMove{}},
+		{name: "last page first", update: []int{4, 1, 2, 3}, kept: []int{1, 2, 3}, moves: []pageMove{{page: 4, to: 3, after: 3}}},
+		{name: "first page last", update: []int{2, 3, 4, 1}, kept: []int{2, 3, 4}, moves: []pageMove{{page: 1, to: 0, after: -1}}},
+		{name: "swapped pairs", update: []int{2, 1, 4, 3}, kept: []int{1, 3}, moves: []pageMove{{page: 2, to: 1, after: 1}, {page: 4, to: 3, after: 3}}},
+		{name: "reversed", update: []int{4, 3, 2, 1}, kept: []int{1}, moves: []pageMove{{page: 2, to: 1, after: 1}, {page: 3, to: 2, after: 2}, {page: 4, to: 3, after: 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applicableRules := calcApplicableRules(order, tt.update)
+			correctOrder, err := calcCorrectOrder(tt.update, applicableRules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := explainUpdate(tt.update, applicableRules, correctOrder)
+			if !reflect.DeepEqual(e.kept, tt.kept) {
+				t.Errorf("kept: got %v, want %v", e.kept, tt.kept)
+			}
+			if !reflect.DeepEqual(e.moves, tt.moves) {
+				t.Errorf("moves: got %+v, want %+v", e.moves, tt.moves)
+			}
+			if len(e.kept)+len(e.moves) != len(tt.update) {
+				t.Errorf("%d pages kept and %d moved out of %d", len(e.kept), len(e.moves), len(tt.update))
+			}
+		})
+	}
+}
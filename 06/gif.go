@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+)
+
+// Palette indices for the GIF export
+const (
+	gifBackground uint8 = iota
+	gifObstacle
+	gifGuard
+	gifLoop
+	gifTrail // First of the trail shades, brightest first
+)
+
+// Number of shades the trail fades through as it ages
+const gifTrailShades = 8
+
+// gifOptions controls the animation
+type gifOptions struct {
+	fps           int // Frames per second
+	cellSize      int // Width and height of a cell in pixels
+	stepsPerFrame int // Guard steps per frame, 0 to pick one that keeps the animation short
+	fade          int // Steps for the trail to fade to its dimmest shade
+}
+
+// Maximum number of frames when the steps per frame is picked automatically, not counting the last step
+const gifMaxFrames = 400
+
+// gifPalette returns the colours of the animation
+func gifPalette() color.Palette {
+	p := color.Palette{
+		gifBackground: color.RGBA{0x0f, 0x0f, 0x23, 0xff}, // Dark blue
+		gifObstacle:   color.RGBA{0x99, 0x99, 0x99, 0xff}, // Grey
+		gifGuard:      color.RGBA{0xff, 0xff, 0x66, 0xff}, // Yellow
+		gifLoop:       color.RGBA{0xff, 0x33, 0x33, 0xff}, // Red
+	}
+	// Trail fades from bright cyan towards the background
+	for i := 0; i < gifTrailShades; i++ {
+		scale := 1 - float64(i)/float64(gifTrailShades+2)
+		p = append(p, color.RGBA{0x00, uint8(0xcc * scale), uint8(0xcc * scale), 0xff})
+	}
+	return p
+}
+
+// exportGIF writes an animation of the guard walking its path to the named file
+// Cells where part 2 found an obstruction would cause a loop are shown on a final, held frame
+func exportGIF(fname string, cells [][]cell, path []cell, loops [][2]int, opts gifOptions) error {
+	if len(path) == 0 {
+		return fmt.Errorf("error exporting gif: empty path")
+	}
+	height, width := len(cells), len(cells[0])
+	size := max(opts.cellSize, 1)
+	delay := max(100/max(opts.fps, 1), 1) // In 100ths of a second
+	stepsPerFrame := opts.stepsPerFrame
+	if stepsPerFrame <= 0 {
+		// Rounded up, or a path just over a multiple of gifMaxFrames gets nearly twice as many frames
+		stepsPerFrame = max((len(path)+gifMaxFrames-1)/gifMaxFrames, 1)
+	}
+	fade := max(opts.fade, 1)
+
+	palette := gifPalette()
+	anim := &gif.GIF{}
+	lastVisit := make([]int, width*height) // Step each cell was last visited, -1 if never
+	for i := range lastVisit {
+		lastVisit[i] = -1
+	}
+
+	// draw renders the map as it is after the given step
+	draw := func(now int, showLoops bool) *image.Paletted {
+		img := image.NewPaletted(image.Rect(0, 0, width*size, height*size), palette)
+		for y, row := range cells {
+			for x, c := range row {
+				idx := gifBackground
+				switch {
+				case c.val == blockedRune:
+					idx = gifObstacle
+				case lastVisit[y*width+x] >= 0:
+					age := now - lastVisit[y*width+x]
+					idx = gifTrail + uint8(min(age*gifTrailShades/fade, gifTrailShades-1))
+				}
+				fillCell(img, x, y, size, idx)
+			}
+		}
+		if showLoops {
+			for _, l := range loops {
+				fillCell(img, l[0], l[1], size, gifLoop)
+			}
+		}
+		g := path[now]
+		fillCell(img, g.x, g.y, size, gifGuard)
+		return img
+	}
+
+	for i, c := range path {
+		lastVisit[c.y*width+c.x] = i
+		if i%stepsPerFrame != 0 && i != len(path)-1 {
+			continue
+		}
+		anim.Image = append(anim.Image, draw(i, false))
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	// Hold the final frame, with any loop obstructions, for a couple of seconds
+	if len(loops) > 0 {
+		anim.Image = append(anim.Image, draw(len(path)-1, true))
+		anim.Delay = append(anim.Delay, 200)
+	}
+
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("error creating file [%s]: %w", fname, err)
+	}
+	defer f.Close()
+
+	if err := gif.EncodeAll(f, anim); err != nil {
+		return fmt.Errorf("error encoding gif [%s]: %w", fname, err)
+	}
+	return f.Close()
+}
+
+// fillCell colours the square of pixels for a grid cell
+func fillCell(img *image.Paletted, x, y, size int, idx uint8) {
+	for py := y * size; py < (y+1)*size; py++ {
+		for px := x * size; px < (x+1)*size; px++ {
+			img.SetColorIndex(px, py, idx)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// meetRule decides what happens when guards patrolling together meet
+type meetRule uint8
+
+const (
+	meetBlock          meetRule = iota // Guards treat each other as obstacles and turn right
+	meetPass                           // Guards walk through each other as if alone on the map
+	meetSwapDirections                 // Guards that collide exchange directions and stay put for that tick
+)
+
+// Maps rule names, as given on the command line, to rules
+var meetRules = map[string]meetRule{
+	"block":           meetBlock,
+	"pass":            meetPass,
+	"swap-directions": meetSwapDirections,
+}
+
+// Returns the name of the rule
+func (r meetRule) String() string {
+	for name, rule := range meetRules {
+		if rule == r {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// parseMeetRule returns the rule with the given name
+func parseMeetRule(name string) (meetRule, error) {
+	r, ok := meetRules[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown meet rule %q, expected block, pass or swap-directions", name)
+	}
+	return r, nil
+}
+
+// fleetResult is the outcome of several guards patrolling in lock-step
+type fleetResult struct {
+	guards []guard // Final state of each guard, with its whole path
+	left   []int   // Tick each guard walked off the map, -1 if it never did
+	ticks  int     // Number of ticks simulated
+	end    error   // nil when every guard left the map, errLoop when the guards repeat a state
+}
+
+// plan is what a guard intends to do in a tick
+type plan struct {
+	direction Dir
+	x, y      int  // Cell the guard moves to
+	moves     bool // False when the guard is boxed in or stopped by a meeting
+	leaves    bool // True when the guard walks off the map
+}
+
+// fleetState is the position and direction of every guard between ticks
+type fleetState struct {
+	guards []guard
+	left   []int // Tick each guard walked off the map, -1 while it's still on it
+}
+
+// newFleetState starts the guards where they are, each growing its own copy of its path
+// Without paths the state is a few words per guard, so loop detection can keep several of them
+func newFleetState(guards []guard, paths bool) fleetState {
+	s := fleetState{guards: make([]guard, len(guards)), left: make([]int, len(guards))}
+	for i, g := range guards {
+		g.path = nil
+		if paths {
+			g.path = append([]cell{}, guards[i].path...)
+		}
+		s.guards[i] = g
+		s.left[i] = -1
+	}
+	return s
+}
+
+// gone checks whether every guard has left the map
+func (s fleetState) gone() bool {
+	for _, l := range s.left {
+		if l < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// equal checks whether the same guards are still on the map, in the same positions and directions
+func (s fleetState) equal(o fleetState) bool {
+	for i, g := range s.guards {
+		if (s.left[i] < 0) != (o.left[i] < 0) {
+			return false
+		}
+		h := o.guards[i]
+		if s.left[i] < 0 && (g.currPos.x != h.currPos.x || g.currPos.y != h.currPos.y || g.direction != h.direction) {
+			return false
+		}
+	}
+	return true
+}
+
+// copyFrom overwrites the state with o, leaving out the paths
+func (s fleetState) copyFrom(o fleetState) {
+	for i, g := range o.guards {
+		g.path = nil
+		s.guards[i] = g
+	}
+	copy(s.left, o.left)
+}
+
+// tick moves every guard still on the map, extending the paths of guards that have one
+// Each tick the guards plan their moves in reading order against the map as it was at the start of the
+// tick, then meetings are resolved according to the rule and all moves happen at once
+func (s fleetState) tick(cells [][]cell, rule meetRule, now int) {
+	plans := planMoves(cells, s.guards, s.left, rule)
+	if rule == meetSwapDirections {
+		resolveSwaps(s.guards, s.left, plans)
+	}
+
+	for i, p := range plans {
+		if s.left[i] >= 0 {
+			continue
+		}
+		g := &s.guards[i]
+		g.direction = p.direction
+		switch {
+		case p.leaves:
+			s.left[i] = now
+		case p.moves:
+			g.currPos = cell{x: p.x, y: p.y, val: visitedRune}
+			if g.path != nil {
+				g.path = append(g.path, g.currPos)
+			}
+		}
+	}
+}
+
+// simulateFleet moves every guard one cell per tick until they have all left the map or the fleet loops
+// The fleet loops on the first tick that repeats the positions and directions of an earlier one
+func simulateFleet(cells [][]cell, guards []guard, rule meetRule) fleetResult {
+	ticks, looped := fleetTicks(cells, guards, rule)
+	s := newFleetState(guards, true)
+	for now := 1; now <= ticks; now++ {
+		s.tick(cells, rule, now)
+	}
+
+	res := fleetResult{guards: s.guards, left: s.left, ticks: ticks}
+	if looped {
+		res.end = errLoop
+	}
+	return res
+}
+
+// fleetTicks returns the number of ticks until the fleet first repeats a state and true, or the number
+// of ticks until every guard has left the map and false
+// The guards can take as long as the lowest common multiple of their own loops to repeat a state, so
+// rather than remember every state the loop is found with Brent's algorithm
+func fleetTicks(cells [][]cell, guards []guard, rule meetRule) (int, bool) {
+	length, ticks := fleetCycle(cells, guards, rule)
+	if length == 0 {
+		return ticks, false
+	}
+
+	// The loop starts where a state that far ahead first catches up with one walked from the start
+	tortoise, hare := newFleetState(guards, false), newFleetState(guards, false)
+	for now := 1; now <= length; now++ {
+		hare.tick(cells, rule, now)
+	}
+	start := 0
+	for !tortoise.equal(hare) {
+		start++
+		tortoise.tick(cells, rule, start)
+		hare.tick(cells, rule, start+length)
+	}
+	return start + length, true
+}
+
+// fleetCycle returns the length of the loop the fleet ends up in and the number of ticks walked to find it
+// The length is 0 when every guard leaves the map, after the number of ticks returned
+func fleetCycle(cells [][]cell, guards []guard, rule meetRule) (length, ticks int) {
+	// The hare walks on while the tortoise waits, jumping to the hare every power of two ticks
+	tortoise, hare := newFleetState(guards, false), newFleetState(guards, false)
+	power := 1
+	for !hare.gone() {
+		ticks++
+		length++
+		hare.tick(cells, rule, ticks)
+		if hare.equal(tortoise) {
+			return length, ticks
+		}
+		if length == power {
+			tortoise.copyFrom(hare)
+			power *= 2
+			length = 0
+		}
+	}
+	return 0, ticks
+}
+
+// fleetLoopObstructions returns the cells where a new obstruction would leave the fleet going round in circles
+// Only cells on a guard's path change the patrol, and the guards' starting cells are left free
+func fleetLoopObstructions(cells [][]cell, guards []guard, res fleetResult, rule meetRule) [][2]int {
+	tried := make(map[[2]int]bool)
+	for _, g := range guards {
+		tried[[2]int{g.currPos.x, g.currPos.y}] = true
+	}
+
+	var loops [][2]int
+	for _, g := range res.guards {
+		for _, c := range g.path {
+			pos := [2]int{c.x, c.y}
+			if tried[pos] {
+				continue
+			}
+			tried[pos] = true
+
+			val := cells[c.y][c.x].val
+			cells[c.y][c.x].val = blockedRune
+			if length, _ := fleetCycle(cells, guards, rule); length > 0 {
+				loops = append(loops, pos)
+			}
+			cells[c.y][c.x].val = val
+		}
+	}
+	return loops
+}
+
+// planMoves works out where each guard still on the map heads next
+// Under the block rule the cells of other guards, and cells already claimed this tick, count as obstacles
+func planMoves(cells [][]cell, guards []guard, left []int, rule meetRule) []plan {
+	occupied := make(map[[2]int]int) // Guard standing on each cell at the start of the tick
+	for i, g := range guards {
+		if left[i] < 0 {
+			occupied[[2]int{g.currPos.x, g.currPos.y}] = i
+		}
+	}
+	claimed := make(map[[2]int]bool)
+
+	plans := make([]plan, len(guards))
+	for i, g := range guards {
+		if left[i] >= 0 {
+			continue
+		}
+		p := plan{direction: g.direction}
+		for tries := 0; tries < int(numDirs); tries++ {
+			m := moves[p.direction]
+			x, y := g.currPos.x+m.dx, g.currPos.y+m.dy
+			if !inBounds(x, y, cells) {
+				p.leaves = true
+				break
+			}
+			blocked := cells[y][x].val == blockedRune
+			if rule == meetBlock {
+				other, taken := occupied[[2]int{x, y}]
+				blocked = blocked || (taken && other != i) || claimed[[2]int{x, y}]
+			}
+			if !blocked {
+				p.x, p.y, p.moves = x, y, true
+				break
+			}
+			p.direction = p.direction.turnRight()
+		}
+		if p.moves && rule == meetBlock {
+			claimed[[2]int{p.x, p.y}] = true
+		}
+		plans[i] = p
+	}
+	return plans
+}
+
+// resolveSwaps finds guards that would collide and has each pair exchange directions instead of moving
+// Guards collide when they head for the same cell, when they would walk through each other, or when one
+// heads for the cell of a guard that isn't moving. A guard meets at most one other guard per tick, so a
+// guard running into one that has already met waits where it is. Guards that stop can be run into in
+// turn, so collisions are checked again until every plan is settled.
+func resolveSwaps(guards []guard, left []int, plans []plan) {
+	met := make([]bool, len(guards))
+	for changed := true; changed; {
+		changed = false
+		for i := range guards {
+			if left[i] >= 0 || !plans[i].moves {
+				continue
+			}
+			for j := range guards {
+				if j == i || left[j] >= 0 || plans[j].leaves {
+					continue
+				}
+				if !collide(guards[i], plans[i], guards[j], plans[j]) {
+					continue
+				}
+				if met[j] {
+					plans[i].moves = false
+				} else {
+					plans[i].direction, plans[j].direction = plans[j].direction, plans[i].direction
+					plans[i].moves, plans[j].moves = false, false
+					met[i], met[j] = true, true
+				}
+				changed = true
+				break
+			}
+		}
+	}
+}
+
+// collide checks whether guard a, which is moving, runs into guard b
+func collide(a guard, pa plan, b guard, pb plan) bool {
+	if !pb.moves {
+		return pa.x == b.currPos.x && pa.y == b.currPos.y
+	}
+	sameTarget := pa.x == pb.x && pa.y == pb.y
+	crossing := pa.x == b.currPos.x && pa.y == b.currPos.y && pb.x == a.currPos.x && pb.y == a.currPos.y
+	return sameTarget || crossing
+}
+
+// fleetCoverage counts the cells covered by each guard and by the guards together
+type fleetCoverage struct {
+	distinct []int // Distinct cells visited by each guard
+	shared   []int // Cells visited by each guard that another guard also visited
+	union    int   // Cells visited by any guard
+	byGuards []int // byGuards[k] is the number of cells visited by exactly k guards
+}
+
+// coverage tallies which guards visited each cell
+func coverage(guards []guard) fleetCoverage {
+	visitors := make(map[[2]int]int) // Number of guards that visited each cell
+	perGuard := make([]map[[2]int]bool, len(guards))
+	for i, g := range guards {
+		perGuard[i] = make(map[[2]int]bool)
+		for _, c := range g.path {
+			pos := [2]int{c.x, c.y}
+			if !perGuard[i][pos] {
+				perGuard[i][pos] = true
+				visitors[pos]++
+			}
+		}
+	}
+
+	cov := fleetCoverage{
+		distinct: make([]int, len(guards)),
+		shared:   make([]int, len(guards)),
+		union:    len(visitors),
+		byGuards: make([]int, len(guards)+1),
+	}
+	for _, n := range visitors {
+		cov.byGuards[n]++
+	}
+	for i, cells := range perGuard {
+		cov.distinct[i] = len(cells)
+		for pos := range cells {
+			if visitors[pos] > 1 {
+				cov.shared[i]++
+			}
+		}
+	}
+	return cov
+}
+
+// printFleet writes the coverage of each guard and of the guards together
+func printFleet(w io.Writer, start []guard, res fleetResult, rule meetRule) {
+	ending := "all guards left the map"
+	if res.end != nil {
+		ending = res.end.Error()
+	}
+	fmt.Fprintf(w, "Finished patrol of %d guards (meet rule: %s) after %d ticks: %s\n", len(start), rule, res.ticks, ending)
+
+	cov := coverage(res.guards)
+	for i, g := range res.guards {
+		s := start[i].currPos
+		fmt.Fprintf(w, "\t Guard %d from (%d,%d) facing %s: %d steps, %d distinct, %d shared",
+			i+1, s.x, s.y, start[i].direction, len(g.path)-1, cov.distinct[i], cov.shared[i])
+		if res.left[i] >= 0 {
+			fmt.Fprintf(w, ", left the map at tick %d\n", res.left[i])
+		} else {
+			fmt.Fprintf(w, ", still on the map at (%d,%d)\n", g.currPos.x, g.currPos.y)
+		}
+	}
+
+	fmt.Fprintf(w, "\t Cells covered by any guard: %d\n", cov.union)
+	fmt.Fprintf(w, "\t Cells covered by more than one guard: %d\n", cov.union-cov.byGuards[1])
+	// With more than two guards, break the shared cells down by how many guards covered them
+	if len(res.guards) > 2 {
+		for k := 2; k < len(cov.byGuards); k++ {
+			fmt.Fprintf(w, "\t Cells covered by exactly %d guards: %d\n", k, cov.byGuards[k])
+		}
+	}
+}
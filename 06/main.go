@@ -1,10 +1,16 @@
 package main
 
 import (
-	"bufio"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/logging"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 6: Challenge
@@ -17,25 +23,47 @@ const (
 	blockedRune rune   = '#'         // Rune representing a blocked cell
 )
 
+// Dir is a direction the guard can face, in clockwise order
+type Dir uint8
+
+const (
+	up Dir = iota
+	right
+	down
+	left
+	numDirs // Number of directions
+)
+
 var (
 	// Maps arrow characters to their respective directions
-	arrowToDir map[rune]string = map[rune]string{
-		'v': "down",
-		'^': "up",
-		'>': "right",
-		'<': "left",
+	arrowToDir = map[rune]Dir{
+		'v': down,
+		'^': up,
+		'>': right,
+		'<': left,
+	}
+	// Movement deltas for each direction
+	moves = [numDirs]move{
+		up:    {0, -1},
+		right: {1, 0},
+		down:  {0, 1},
+		left:  {-1, 0},
 	}
-	// Array of possible directions for traversal
-	directions = []string{"up", "right", "down", "left"}
-	// Maps directions to their respective movement deltas
-	moves = map[string]move{
-		"up":    {0, -1},
-		"right": {1, 0},
-		"down":  {0, 1},
-		"left":  {-1, 0},
+	// Names of each direction
+	dirNames = [numDirs]string{
+		up:    "up",
+		right: "right",
+		down:  "down",
+		left:  "left",
 	}
 )
 
+// Errors ending a patrol step by step
+var (
+	errOffMap  = errors.New("no more valid moves")      // The guard walked off the map
+	errBoxedIn = errors.New("no valid moves available") // Obstacles on all sides
+)
+
 // Represents a movement with x and y deltas
 type move struct {
 	dx, dy int
@@ -51,102 +79,167 @@ type cell struct {
 type guard struct {
 	currPos   cell
 	path      []cell
-	direction string
+	direction Dir
 }
 
 func main() {
-	// Read the grid and initialize the guard's state
-	cells, guard, err := readInput(fileName)
+	tui := flag.Bool("tui", false, "step through the patrol in an interactive terminal viewer")
+	speed := flag.Int("speed", 20, "steps per second when playing the patrol in the viewer")
+	exportGif := flag.String("export-gif", "", "write an animation of the patrol to this GIF file")
+	gifFPS := flag.Int("gif-fps", 25, "frames per second of the GIF animation")
+	gifCell := flag.Int("gif-cell", 4, "size of a map cell in the GIF animation, in pixels")
+	gifSteps := flag.Int("gif-steps", 0, "guard steps per GIF frame, 0 picks one that keeps the animation short")
+	gifFade := flag.Int("gif-fade", 300, "steps for the trail in the GIF animation to fade")
+	meet := flag.String("meet", "block", "what guards do when they meet on a map with several guards: block, pass or swap-directions")
+	run := result.Main(6, fileName)
+
+	// Read the grid and initialize the guards' states
+	cells, guards, err := readInput(fileName)
 	if err != nil {
-		os.Exit(1)
+		run.Fatal(err)
 	}
-	count := 0
+	slog.Debug("read map", "width", len(cells[0]), "height", len(cells), "guards", len(guards))
 
-	// Simulate the guard's traversal until no moves are available
-	for {
-		guard, cells, err = step(guard, cells)
+	// Several guards patrol together: the cells any of them covered stand in for part 1 and the
+	// obstructions that trap the fleet in a loop for part 2. Each guard's coverage is written to
+	// stderr so it doesn't mix with the results
+	if len(guards) > 1 {
+		if *tui || *exportGif != "" {
+			slog.Error("the viewer and GIF export follow a single guard", "guards", len(guards))
+			os.Exit(1)
+		}
+		rule, err := parseMeetRule(*meet)
 		if err != nil {
-			// Print final results when traversal ends
-			fmt.Printf("Finished traversal. Total steps: %d\n", count)
-			fmt.Printf("\t Total distinct steps: %d\n", distinctPositions(cells))
-			break
+			slog.Error("parsing meet rule", "err", err)
+			os.Exit(1)
+		}
+		var res fleetResult
+		run.Part(1, func() (any, error) {
+			res = simulateFleet(cells, guards, rule)
+			return coverage(res.guards).union, nil
+		})
+		run.Part(2, func() (any, error) {
+			if res.end != nil {
+				return nil, fmt.Errorf("the guards already loop without an obstruction after %d ticks", res.ticks)
+			}
+			loops := fleetLoopObstructions(cells, guards, res, rule)
+			for _, l := range loops {
+				logging.Trace("loop obstruction", "x", l[0], "y", l[1])
+			}
+			return len(loops), nil
+		})
+		printFleet(os.Stderr, guards, res, rule)
+		run.Finish()
+		return
+	}
+	guard := guards[0]
+
+	// Watch the guard patrol instead of printing the answers
+	if *tui {
+		if err := newViewer(cells, guard, *speed).run(); err != nil {
+			slog.Error("running viewer", "err", err)
+			os.Exit(1)
 		}
-		// // Print the grid and guard's position after each step
-		// fmt.Println(puzzleToString(cells))
-		// fmt.Println(guardPosToString(guard))
-		// fmt.Println("\n---------------------\n")
-		count++
+		return
 	}
-}
 
-// Reads the input file and initializes the grid and guard's starting state
-func readInput(fname string) ([][]cell, guard, error) {
-	cells := make([][]cell, 0) // 2D array representing the grid
-	guardPath := make([]cell, 0)
-	gu := guard{
-		path: guardPath,
+	// Part 1: Walk the guard off the map using the jump table
+	pm := newPatrolMap(cells)
+	var res patrolResult
+	run.Part(1, func() (any, error) {
+		res = pm.patrol(guard.currPos, guard.direction)
+		slog.Debug("finished traversal", "steps", res.steps, "distinct", res.distinct)
+		return res.distinct, nil
+	})
+
+	// Part 2: Count the obstructions that would trap the guard in a loop, starting from part 1's path
+	var loops [][2]int
+	run.Part(2, func() (any, error) {
+		loops = pm.loopObstructions(guard.currPos, guard.direction, res.visited)
+		for _, l := range loops {
+			logging.Trace("loop obstruction", "x", l[0], "y", l[1])
+		}
+		return len(loops), nil
+	})
+
+	run.Finish()
+
+	// Animate the guard's path, walked one step at a time
+	if *exportGif != "" {
+		tl := simulate(copyCells(cells), guard)
+		path := tl.frames[len(tl.frames)-1].path
+		opts := gifOptions{fps: *gifFPS, cellSize: *gifCell, stepsPerFrame: *gifSteps, fade: *gifFade}
+		if err := exportGIF(*exportGif, cells, path, loops, opts); err != nil {
+			slog.Error("exporting gif", "err", err)
+			os.Exit(1)
+		}
 	}
+}
 
+// Reads the input file and initializes the grid and the guards' starting states
+func readInput(fname string) ([][]cell, []guard, error) {
 	file, err := os.Open(fname)
 	if err != nil {
-		return nil, guard{}, fmt.Errorf("error opening file: %w", err)
+		return nil, nil, fmt.Errorf("error opening file: %w", err)
 	}
 	defer file.Close()
 
-	reader := bufio.NewReader(file)
-	var row []cell
-	var i, j int
+	cells, guards, err := parseMap(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading file [%s]: %w", fname, err)
+	}
+	return cells, guards, nil
+}
 
-	for {
-		char, _, err := reader.ReadRune()
-		if err != nil {
-			if err.Error() == "EOF" {
-				if len(row) > 0 {
-					cells = append(cells, row) // Append the last row to the grid
-				}
-				break
-			}
-			return nil, guard{}, fmt.Errorf("error reading file :%w", err)
-		}
+// Parses a map into the grid and the starting state of every guard on it
+// Guards are returned in reading order, left to right and top to bottom
+// Maps can be any rectangular size, every line must have the same number of cells
+func parseMap(r io.Reader) ([][]cell, []guard, error) {
+	grid, err := input.Grid(r)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		if char == '\n' {
-			// Start a new row when encountering a newline
-			cells = append(cells, row)
-			row = make([]cell, 0)
-			j++
-			i = 0
-			continue
-		}
+	cells := make([][]cell, len(grid)) // 2D array representing the grid
+	guards := make([]guard, 0)
+	for j, row := range grid {
+		cells[j] = make([]cell, len(row))
+		for i, char := range row {
+			currCell := cell{x: i, y: j, val: char}
 
-		currCell := cell{x: i, y: j, val: char}
-
-		// Identify the guard's starting position and direction
-		dir, ok := arrowToDir[char]
-		if ok {
-			// mark starting position as visted and add it to the guard path
-			char = visitedRune
-			currCell.val = visitedRune
-			gu.path = append(gu.path, currCell)
-			gu.currPos = currCell
-			gu.direction = dir
+			// Identify each guard's starting position and direction
+			dir, ok := arrowToDir[char]
+			if ok {
+				// mark starting position as visted and start the guard's path there
+				currCell.val = visitedRune
+				guards = append(guards, guard{
+					currPos:   currCell,
+					path:      []cell{currCell},
+					direction: dir,
+				})
+			}
+			cells[j][i] = currCell
 		}
-		row = append(row, currCell)
-		i++
 	}
-	return cells, gu, nil
+
+	if len(guards) == 0 {
+		return nil, nil, &input.ParseError{Err: errors.New("no guard on the map")}
+	}
+
+	return cells, guards, nil
 }
 
 // Simulates a single step of the guard's movement
 func step(g guard, cells [][]cell) (guard, [][]cell, error) {
 	directionsTried := 0
-	for directionsTried < len(directions) {
+	for directionsTried < int(numDirs) {
 		move := moves[g.direction]
 		newX := g.currPos.x + move.dx
 		newY := g.currPos.y + move.dy
 
 		// Stop traversal if out of bounds
 		if !inBounds(newX, newY, cells) {
-			return g, cells, errors.New("no more valid moves")
+			return g, cells, errOffMap
 		}
 		// Check if the move is valid
 		if cells[newY][newX].val != blockedRune {
@@ -159,32 +252,28 @@ func step(g guard, cells [][]cell) (guard, [][]cell, error) {
 		}
 
 		// Rotate to the next direction if the current move is invalid
-		g.direction = turnRight(g.direction)
+		g.direction = g.direction.turnRight()
 		directionsTried++
 	}
 
 	// Return an error if no valid moves are available
-	return g, cells, errors.New("no valid moves available")
+	return g, cells, errBoxedIn
 }
 
-// Rotates the guard's direction 90 degrees clockwise
-func turnRight(dir string) string {
-	switch dir {
-	case "up":
-		return "right"
-	case "right":
-		return "down"
-	case "down":
-		return "left"
-	case "left":
-		return "up"
-	}
-	return ""
+// Rotates the direction 90 degrees clockwise
+func (d Dir) turnRight() Dir {
+	return (d + 1) % numDirs
+}
+
+// Returns the name of the direction
+func (d Dir) String() string {
+	return dirNames[d]
 }
 
 // Checks if the given coordinates are within the grid bounds
+// The grid is indexed cells[y][x]
 func inBounds(x, y int, cells [][]cell) bool {
-	return x >= 0 && x < len(cells) && y >= 0 && y < len(cells[x])
+	return y >= 0 && y < len(cells) && x >= 0 && x < len(cells[y])
 }
 
 // Counts the number of distinct visited cells
@@ -199,20 +288,3 @@ func distinctPositions(s [][]cell) int {
 	}
 	return count
 }
-
-// Formats the guard's current position as a string
-func guardPosToString(g guard) string {
-	return fmt.Sprintf("guard pos: (%d,%d)\n", g.currPos.x, g.currPos.y)
-}
-
-// Converts the grid into a printable string representation
-func puzzleToString(s [][]cell) string {
-	var export string
-	for _, row := range s {
-		for _, cell := range row {
-			export += fmt.Sprintf("%s ", string(cell.val))
-		}
-		export += "\n"
-	}
-	return export
-}
@@ -0,0 +1,731 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image/gif"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"testing/quick"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+// randomMap is a random rectangular map with a single guard, generated by testing/quick
+type randomMap struct {
+	text string
+}
+
+// Generate creates a map of up to 12x12 cells with a random density of obstacles
+func (randomMap) Generate(r *rand.Rand, _ int) reflect.Value {
+	width, height := 1+r.Intn(12), 1+r.Intn(12)
+	density := r.Float64() * 0.35
+	arrows := []rune{'^', '>', 'v', '<'}
+	gx, gy := r.Intn(width), r.Intn(height)
+
+	var sb strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			switch {
+			case x == gx && y == gy:
+				sb.WriteRune(arrows[r.Intn(len(arrows))])
+			case r.Float64() < density:
+				sb.WriteRune(blockedRune)
+			default:
+				sb.WriteRune(openRune)
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	return reflect.ValueOf(randomMap{text: sb.String()})
+}
+
+// referencePatrol walks the guard one cell at a time with step, the slow but obvious simulation
+// Returns the number of moves, the number of distinct cells visited and whether the guard never leaves
+func referencePatrol(cells [][]cell, g guard) (int, int, bool) {
+	seen := make(map[[3]int]bool)
+	var steps int
+	for {
+		state := [3]int{g.currPos.x, g.currPos.y, int(g.direction)}
+		if seen[state] {
+			return steps, distinctPositions(cells), true
+		}
+		seen[state] = true
+
+		var err error
+		g, cells, err = step(g, cells)
+		if errors.Is(err, errOffMap) {
+			return steps, distinctPositions(cells), false
+		}
+		if errors.Is(err, errBoxedIn) {
+			return steps, distinctPositions(cells), true
+		}
+		steps++
+	}
+}
+
+// referenceLoopObstructions tries an obstacle on every open cell and counts the ones that trap the guard
+func referenceLoopObstructions(text string) int {
+	cells, guards, _ := parseMap(strings.NewReader(text))
+	g := guards[0]
+
+	var count int
+	for y, row := range cells {
+		for x, c := range row {
+			if c.val == blockedRune || (x == g.currPos.x && y == g.currPos.y) {
+				continue
+			}
+			// Fresh copy of the map with the extra obstacle
+			blocked, bg, _ := parseMap(strings.NewReader(text))
+			blocked[y][x].val = blockedRune
+			if _, _, loops := referencePatrol(blocked, bg[0]); loops {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestPatrolMatchesReference(t *testing.T) {
+	property := func(m randomMap) bool {
+		cells, guards, err := parseMap(strings.NewReader(m.text))
+		if err != nil {
+			t.Logf("parse error: %s\n%s", err, m.text)
+			return false
+		}
+		g := guards[0]
+		wantSteps, wantDistinct, loops := referencePatrol(cells, g)
+		if loops {
+			return true // A guard that never leaves has no final count to compare
+		}
+
+		cells, guards, _ = parseMap(strings.NewReader(m.text))
+		g = guards[0]
+		res := newPatrolMap(cells).patrol(g.currPos, g.direction)
+		if res.steps != wantSteps || res.distinct != wantDistinct {
+			t.Logf("got %d steps, %d distinct, want %d steps, %d distinct\n%s", res.steps, res.distinct, wantSteps, wantDistinct, m.text)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoopObstructionsMatchReference(t *testing.T) {
+	property := func(m randomMap) bool {
+		cells, guards, err := parseMap(strings.NewReader(m.text))
+		if err != nil {
+			t.Logf("parse error: %s\n%s", err, m.text)
+			return false
+		}
+		g := guards[0]
+		if _, _, loops := referencePatrol(cells, g); loops {
+			return true // Only maps the guard can leave have a part 2 answer
+		}
+
+		cells, guards, _ = parseMap(strings.NewReader(m.text))
+		g = guards[0]
+		pm := newPatrolMap(cells)
+		route := pm.patrol(g.currPos, g.direction).visited
+		got := len(pm.loopObstructions(g.currPos, g.direction, route))
+		want := referenceLoopObstructions(m.text)
+		if got != want {
+			t.Logf("got %d loop obstructions, want %d\n%s", got, want, m.text)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{name: "wide", text: "..#...\n.^....\n"},
+		{name: "tall", text: "..\n#.\n..\n..\n.v\n"},
+		{name: "no trailing newline", text: "...\n.>.\n..."},
+		{name: "windows line endings", text: "\xef\xbb\xbf...\r\n.>.\r\n...\r\n\r\n"},
+		{name: "trailing whitespace", text: "...  \n.>.\t\n...\n\n\n"},
+		{name: "ragged", text: "....\n.^.\n....\n", wantErr: "line 2: ragged grid: 3 cells, expected 4"},
+		{name: "empty", text: "", wantErr: "empty grid"},
+		{name: "no guard", text: "...\n...\n", wantErr: "no guard on the map"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseMap(strings.NewReader(tt.text))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNonSquarePatrol(t *testing.T) {
+	// 2 wide and 5 tall: the guard walks down the right hand column and off the bottom
+	text := ".v\n..\n..\n..\n..\n"
+	cells, guards, err := parseMap(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps, distinct, loops := referencePatrol(cells, guards[0])
+	if loops || steps != 4 || distinct != 5 {
+		t.Fatalf("got %d steps, %d distinct, loops %t, want 4 steps, 5 distinct", steps, distinct, loops)
+	}
+}
+
+func TestParseMapKeepsEveryGuard(t *testing.T) {
+	_, guards, err := parseMap(strings.NewReader("v..\n...\n..^\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(guards) != 2 {
+		t.Fatalf("got %d guards, want 2", len(guards))
+	}
+	first, second := guards[0], guards[1]
+	if first.currPos.x != 0 || first.currPos.y != 0 || first.direction != down {
+		t.Errorf("first guard at (%d,%d) facing %s, want (0,0) facing down", first.currPos.x, first.currPos.y, first.direction)
+	}
+	if second.currPos.x != 2 || second.currPos.y != 2 || second.direction != up {
+		t.Errorf("second guard at (%d,%d) facing %s, want (2,2) facing up", second.currPos.x, second.currPos.y, second.direction)
+	}
+}
+
+func TestFleetMeetRules(t *testing.T) {
+	// Two guards walking towards each other along a corridor
+	text := ">...<\n"
+	tests := []struct {
+		rule     meetRule
+		wantEnd  error
+		distinct []int // Distinct cells covered by each guard
+		shared   int   // Cells covered by both guards
+	}{
+		// Both head for the middle, the first guard claims it and the second turns up off the map
+		{rule: meetBlock, wantEnd: nil, distinct: []int{5, 2}, shared: 2},
+		// The guards cross and walk the whole corridor
+		{rule: meetPass, wantEnd: nil, distinct: []int{5, 5}, shared: 5},
+		// Both head for the middle, so they exchange directions and walk back the way they came
+		{rule: meetSwapDirections, wantEnd: nil, distinct: []int{2, 2}, shared: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule.String(), func(t *testing.T) {
+			cells, guards, err := parseMap(strings.NewReader(text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := simulateFleet(cells, guards, tt.rule)
+			if res.end != tt.wantEnd {
+				t.Fatalf("got end %v, want %v", res.end, tt.wantEnd)
+			}
+			cov := coverage(res.guards)
+			if !reflect.DeepEqual(cov.distinct, tt.distinct) {
+				t.Errorf("got distinct %v, want %v", cov.distinct, tt.distinct)
+			}
+			if got := cov.union - cov.byGuards[1]; got != tt.shared {
+				t.Errorf("got %d shared cells, want %d", got, tt.shared)
+			}
+		})
+	}
+}
+
+func TestSwapLeavesNoSharedCells(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		// The first guard steps towards the second, which then meets the third and stays put
+		{name: "queue", text: ">>.<\n"},
+		// Two guards head for the middle while a third walks into one of them from the side
+		{name: "crossroads", text: "v..\n>.<\n"},
+		// Guards packed in a row all facing the same way
+		{name: "convoy", text: ">>>.<\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells, guards, err := parseMap(strings.NewReader(tt.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			left := make([]int, len(guards))
+			for i := range left {
+				left[i] = -1
+			}
+			plans := planMoves(cells, guards, left, meetSwapDirections)
+			resolveSwaps(guards, left, plans)
+
+			at := make(map[[2]int]int)
+			for i, g := range guards {
+				if plans[i].leaves {
+					continue
+				}
+				pos := [2]int{g.currPos.x, g.currPos.y}
+				if plans[i].moves {
+					pos = [2]int{plans[i].x, plans[i].y}
+				}
+				if other, ok := at[pos]; ok {
+					t.Fatalf("guards %d and %d both end the tick on %v", other+1, i+1, pos)
+				}
+				at[pos] = i
+			}
+		})
+	}
+}
+
+func TestFleetPassMatchesSingleGuards(t *testing.T) {
+	// With the pass rule guards never interact, so each covers what it would alone
+	text := "....#.....\n.........#\n..........\n..#.......\n.......#..\n..........\n.#..^.....\n........#.\n#.........\n......#.>.\n"
+	cells, guards, err := parseMap(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := simulateFleet(cells, guards, meetPass)
+	if res.end != nil {
+		t.Fatalf("unexpected end %v", res.end)
+	}
+	cov := coverage(res.guards)
+	pm := newPatrolMap(cells)
+	for i, g := range guards {
+		want := pm.patrol(g.currPos, g.direction)
+		if cov.distinct[i] != want.distinct || len(res.guards[i].path)-1 != want.steps {
+			t.Errorf("guard %d: got %d steps, %d distinct, want %d steps, %d distinct",
+				i+1, len(res.guards[i].path)-1, cov.distinct[i], want.steps, want.distinct)
+		}
+	}
+}
+
+// referenceFleetTicks finds the fleet's first repeated state by remembering every state it has been in
+func referenceFleetTicks(cells [][]cell, guards []guard, rule meetRule) (int, bool) {
+	s := newFleetState(guards, false)
+	key := func() string {
+		var b strings.Builder
+		for i, g := range s.guards {
+			if s.left[i] >= 0 {
+				b.WriteString("-;")
+				continue
+			}
+			fmt.Fprintf(&b, "%d,%d,%d;", g.currPos.x, g.currPos.y, g.direction)
+		}
+		return b.String()
+	}
+	seen := map[string]bool{key(): true}
+	for ticks := 1; !s.gone(); ticks++ {
+		s.tick(cells, rule, ticks)
+		if seen[key()] {
+			return ticks, true
+		}
+		seen[key()] = true
+	}
+	return slices.Max(s.left), false
+}
+
+func TestFleetTicksMatchReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	arrows := []byte{'^', '>', 'v', '<'}
+	for n := 0; n < 300; n++ {
+		// Small maps with two or three guards, so the guards meet and loop often
+		width, height := 3+rng.Intn(5), 3+rng.Intn(5)
+		rows := make([][]byte, height)
+		for y := range rows {
+			rows[y] = make([]byte, width)
+			for x := range rows[y] {
+				rows[y][x] = byte(openRune)
+				if rng.Intn(5) == 0 {
+					rows[y][x] = byte(blockedRune)
+				}
+			}
+		}
+		for g := 0; g < 2+rng.Intn(2); g++ {
+			rows[rng.Intn(height)][rng.Intn(width)] = arrows[rng.Intn(len(arrows))]
+		}
+		text := string(bytes.Join(rows, []byte("\n"))) + "\n"
+
+		cells, guards, err := parseMap(strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rule := range []meetRule{meetBlock, meetPass, meetSwapDirections} {
+			gotTicks, gotLoop := fleetTicks(cells, guards, rule)
+			wantTicks, wantLoop := referenceFleetTicks(cells, guards, rule)
+			if gotTicks != wantTicks || gotLoop != wantLoop {
+				t.Fatalf("%s rule on\n%s: got %d ticks, loop %t, want %d ticks, loop %t",
+					rule, text, gotTicks, gotLoop, wantTicks, wantLoop)
+			}
+			res := simulateFleet(cells, guards, rule)
+			if res.ticks != wantTicks || (res.end == errLoop) != wantLoop {
+				t.Fatalf("%s rule on\n%s: simulated %d ticks ending %v, want %d ticks, loop %t",
+					rule, text, res.ticks, res.end, wantTicks, wantLoop)
+			}
+		}
+	}
+}
+
+func TestFleetLoopObstructions(t *testing.T) {
+	// A fleet of one loops exactly where the guard on its own does
+	cells, guards, err := parseMap(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := simulateFleet(cells, guards, meetBlock)
+	if got := len(fleetLoopObstructions(cells, guards, res, meetBlock)); got != 6 {
+		t.Errorf("got %d loop obstructions for one guard, want 6", got)
+	}
+
+	// Under the pass rule the fleet loops when any one guard does, but no guard's start can be obstructed
+	text := strings.Replace(example, "#.........\n", "#..<......\n", 1)
+	cells, guards, err = parseMap(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[[2]int]bool)
+	pm := newPatrolMap(cells)
+	for _, g := range guards {
+		for _, l := range pm.loopObstructions(g.currPos, g.direction, pm.patrol(g.currPos, g.direction).visited) {
+			want[l] = true
+		}
+	}
+	for _, g := range guards {
+		delete(want, [2]int{g.currPos.x, g.currPos.y})
+	}
+	res = simulateFleet(cells, guards, meetPass)
+	got := make(map[[2]int]bool)
+	for _, l := range fleetLoopObstructions(cells, guards, res, meetPass) {
+		got[l] = true
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got loop obstructions %v for two guards, want %v", got, want)
+	}
+}
+
+func FuzzParseMap(f *testing.F) {
+	f.Add("....#.....\n.........#\n..........\n..#.......\n.......#..\n..........\n.#..^.....\n........#.\n#.........\n......#...\n")
+	f.Add("....\n.^.\n")
+	f.Add("<>\nv^\n")
+	f.Add("\n\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		cells, guards, err := parseMap(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		for y, row := range cells {
+			if len(row) != len(cells[0]) {
+				t.Fatalf("row %d has %d cells, row 0 has %d", y, len(row), len(cells[0]))
+			}
+		}
+		for _, g := range guards {
+			if !inBounds(g.currPos.x, g.currPos.y, cells) {
+				t.Fatalf("guard at %d,%d is off the map", g.currPos.x, g.currPos.y)
+			}
+		}
+	})
+}
+
+// Example from the puzzle
+const example = `....#.....
+.........#
+..........
+..#.......
+.......#..
+..........
+.#..^.....
+........#.
+#.........
+......#...
+`
+
+// renderViewer draws the viewer's current frame
+func renderViewer(v *viewer) string {
+	var sb strings.Builder
+	w := bufio.NewWriter(&sb)
+	v.render(w)
+	w.Flush()
+	return sb.String()
+}
+
+func TestViewerRender(t *testing.T) {
+	cells, guards, err := parseMap(strings.NewReader(".#.\n...\n.^.\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newViewer(cells, guards[0], 20)
+
+	dim := func(r string) string { return ansiDim + r + ansiReset }
+	keys := "\r\n" + ansiDim + "space play/pause  n/b step  t/T next/prev turn  g/G start/end  +/- speed  o obstruction  q quit" + ansiReset
+	want := ansiClear +
+		dim(".") + "#" + dim(".") + "\r\n" +
+		dim(".") + dim(".") + dim(".") + "\r\n" +
+		dim(".") + ansiGuard + "^" + ansiReset + dim(".") + "\r\n" +
+		"\r\nstep 0/2  pos (1,2) facing up  paused at 20 steps/s  distinct 1" + keys
+	if got := renderViewer(v); got != want {
+		t.Errorf("first frame: got %q, want %q", got, want)
+	}
+
+	// The guard turns at the obstacle and steps right in one move, leaving a trail
+	v.forward()
+	v.forward()
+	trail := ansiTrail + "X" + ansiReset
+	want = ansiClear +
+		dim(".") + "#" + dim(".") + "\r\n" +
+		dim(".") + trail + ansiGuard + ">" + ansiReset + "\r\n" +
+		dim(".") + trail + dim(".") + "\r\n" +
+		"\r\nstep 2/2  pos (2,1) facing right  paused at 20 steps/s  distinct 3  [no more valid moves]" + keys
+	if got := renderViewer(v); got != want {
+		t.Errorf("last frame: got %q, want %q", got, want)
+	}
+}
+
+func TestViewerWindowFollowsGuard(t *testing.T) {
+	cells, guards, err := parseMap(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newViewer(cells, guards[0], 20)
+	v.rows, v.cols = 7, 3 // A 3x3 window, the rest of the rows are the status
+
+	got := strings.TrimPrefix(renderViewer(v), ansiClear)
+	rows := strings.Split(got, "\r\n")[:3]
+	want := []string{
+		ansiDim + "." + ansiReset + ansiDim + "." + ansiReset + ansiDim + "." + ansiReset,
+		ansiDim + "." + ansiReset + ansiGuard + "^" + ansiReset + ansiDim + "." + ansiReset,
+		ansiDim + "." + ansiReset + ansiDim + "." + ansiReset + ansiDim + "." + ansiReset,
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("got %q, want %q", rows, want)
+	}
+}
+
+func TestViewerNavigation(t *testing.T) {
+	cells, guards, err := parseMap(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newViewer(cells, guards[0], 20)
+	if !errors.Is(v.tl.end, errOffMap) {
+		t.Fatalf("patrol ended with %v, want %v", v.tl.end, errOffMap)
+	}
+
+	v.back() // Already at the start
+	if v.frame != 0 {
+		t.Fatalf("back from the start moved to frame %d", v.frame)
+	}
+
+	// The guard walks up from (4,6) until the obstacle at (4,0) turns it right
+	v.nextTurn()
+	if g := v.tl.frames[v.frame]; g.direction != right || g.currPos.x != 5 || g.currPos.y != 1 {
+		t.Fatalf("next turn: at (%d,%d) facing %s, want (5,1) facing right", g.currPos.x, g.currPos.y, g.direction)
+	}
+	turn := v.frame
+	v.nextTurn()
+	v.prevTurn()
+	if v.frame != turn {
+		t.Fatalf("prev turn: at frame %d, want %d", v.frame, turn)
+	}
+	v.prevTurn() // No earlier turn, back to the start
+	if v.frame != 0 {
+		t.Fatalf("prev turn before the first: at frame %d, want 0", v.frame)
+	}
+
+	for v.forward() {
+	}
+	if v.frame != len(v.tl.frames)-1 {
+		t.Fatalf("forward stopped at frame %d of %d", v.frame, len(v.tl.frames))
+	}
+}
+
+func TestViewerObstruction(t *testing.T) {
+	cells, guards, err := parseMap(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newViewer(cells, guards[0], 20)
+	original := len(v.original.frames)
+
+	// Walking left along row 6, an obstruction at (3,6) is one of the puzzle's loops
+	for v.frame = 0; v.frame < len(v.tl.frames); v.frame++ {
+		if g := v.tl.frames[v.frame]; g.currPos.x == 4 && g.currPos.y == 6 && g.direction == left {
+			break
+		}
+	}
+	v.toggleObstruction()
+	if !v.placed || v.placedX != 3 || v.placedY != 6 {
+		t.Fatalf("placed %t at (%d,%d), want (3,6)", v.placed, v.placedX, v.placedY)
+	}
+	if !errors.Is(v.tl.end, errLoop) {
+		t.Fatalf("patrol ended with %v, want %v", v.tl.end, errLoop)
+	}
+	for v.forward() {
+	}
+	if got := renderViewer(v); !strings.Contains(got, "obstruction at (3,6)") || !strings.Contains(got, "LOOP") {
+		t.Errorf("status doesn't show the loop: %q", got)
+	}
+
+	// Removing it restores the original patrol, which the branch mustn't have changed
+	v.toggleObstruction()
+	if v.placed || !errors.Is(v.tl.end, errOffMap) || len(v.tl.frames) != original {
+		t.Fatalf("after removing: placed %t, end %v, %d frames, want %d", v.placed, v.tl.end, len(v.tl.frames), original)
+	}
+	if v.frame >= len(v.tl.frames) {
+		t.Fatalf("frame %d past the end of %d frames", v.frame, len(v.tl.frames))
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	// Plain keys, arrows as ESC [ and ESC O sequences, Ctrl-arrows with parameters and a lone escape
+	in := "n\x1b[C\x1b[Dq\x1bOC\x1b[1;5Dx\x1bq"
+	want := []string{"n", "right", "left", "q", "right", "left", "x", "q"}
+
+	// One byte per read splits every escape sequence across reads
+	keys := make(chan string)
+	go readKeys(iotest.OneByteReader(strings.NewReader(in)), keys)
+	var got []string
+	for key := range keys {
+		got = append(got, key)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got keys %q, want %q", got, want)
+	}
+}
+
+// decodeGIF exports a path as a GIF and decodes it again
+func decodeGIF(t *testing.T, cells [][]cell, path []cell, loops [][2]int, opts gifOptions) *gif.GIF {
+	t.Helper()
+	fname := filepath.Join(t.TempDir(), "patrol.gif")
+	if err := exportGIF(fname, cells, path, loops, opts); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return anim
+}
+
+func TestExportGIF(t *testing.T) {
+	cells, guards, err := parseMap(strings.NewReader(".#.\n...\n.^.\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl := simulate(copyCells(cells), guards[0])
+	path := tl.frames[len(tl.frames)-1].path
+	loops := [][2]int{{0, 0}}
+	anim := decodeGIF(t, cells, path, loops, gifOptions{fps: 25, cellSize: 2, stepsPerFrame: 1, fade: 300})
+
+	// One frame per step, then the held frame showing the loops
+	if len(anim.Image) != len(path)+1 {
+		t.Fatalf("got %d frames, want %d", len(anim.Image), len(path)+1)
+	}
+	if want := []int{4, 4, 4, 200}; !reflect.DeepEqual(anim.Delay, want) {
+		t.Errorf("delays: got %v, want %v", anim.Delay, want)
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 6 || b.Dy() != 6 {
+		t.Fatalf("frame size %dx%d, want 6x6", b.Dx(), b.Dy())
+	}
+
+	// Colour of the top-left pixel of a cell in a frame
+	at := func(frame, x, y int) uint8 {
+		return anim.Image[frame].ColorIndexAt(x*2, y*2)
+	}
+	tests := []struct {
+		name        string
+		frame, x, y int
+		want        uint8
+	}{
+		{name: "obstacle", frame: 0, x: 1, y: 0, want: gifObstacle},
+		{name: "background", frame: 0, x: 0, y: 0, want: gifBackground},
+		{name: "guard at the start", frame: 0, x: 1, y: 2, want: gifGuard},
+		{name: "guard at the end", frame: 2, x: 2, y: 1, want: gifGuard},
+		{name: "trail", frame: 2, x: 1, y: 2, want: gifTrail},
+		{name: "loop hidden while walking", frame: 2, x: 0, y: 0, want: gifBackground},
+		{name: "loop on the held frame", frame: 3, x: 0, y: 0, want: gifLoop},
+	}
+	for _, tt := range tests {
+		if got := at(tt.frame, tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: got colour %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExportGIFFade(t *testing.T) {
+	// The guard paces a corridor, so the start of the trail ages as it goes
+	cells, _, err := parseMap(strings.NewReader(">........\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := make([]cell, 0)
+	for x := 0; x < 9; x++ {
+		path = append(path, cells[0][x])
+	}
+	anim := decodeGIF(t, cells, path, nil, gifOptions{fps: 25, cellSize: 1, stepsPerFrame: 1, fade: 8})
+
+	last := anim.Image[len(anim.Image)-1]
+	if got := last.ColorIndexAt(0, 0); got != gifTrail+gifTrailShades-1 {
+		t.Errorf("oldest trail: got colour %d, want the dimmest shade %d", got, gifTrail+gifTrailShades-1)
+	}
+	if got := last.ColorIndexAt(7, 0); got != gifTrail+1 {
+		t.Errorf("newest trail: got colour %d, want %d", got, gifTrail+1)
+	}
+	if len(anim.Image) != len(path) {
+		t.Errorf("got %d frames without loops, want %d", len(anim.Image), len(path))
+	}
+}
+
+func TestExportGIFFrameCap(t *testing.T) {
+	cells, _, err := parseMap(strings.NewReader("^\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, steps := range []int{1, gifMaxFrames, gifMaxFrames + 1, 2*gifMaxFrames - 1, 1000, 10*gifMaxFrames + 7} {
+		path := make([]cell, steps)
+		for i := range path {
+			path[i] = cells[0][0]
+		}
+		anim := decodeGIF(t, cells, path, nil, gifOptions{fps: 25, cellSize: 1, fade: 300})
+
+		// The last step gets a frame of its own when it isn't on a multiple of the steps per frame
+		if len(anim.Image) > gifMaxFrames+1 {
+			t.Errorf("%d steps: got %d frames, want at most %d", steps, len(anim.Image), gifMaxFrames+1)
+		}
+		if steps <= gifMaxFrames && len(anim.Image) != steps {
+			t.Errorf("%d steps: got %d frames, want one per step", steps, len(anim.Image))
+		}
+	}
+}
+
+func TestExportGIFEmptyPath(t *testing.T) {
+	cells, _, err := parseMap(strings.NewReader("^\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exportGIF(filepath.Join(t.TempDir(), "patrol.gif"), cells, nil, nil, gifOptions{}); err == nil {
+		t.Fatal("expected an error for an empty path")
+	}
+}
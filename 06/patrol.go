@@ -0,0 +1,211 @@
+package main
+
+// offMap is the jump table entry for a guard that walks off the map before reaching an obstacle
+const offMap int32 = -1
+
+// patrolMap is a flattened copy of the grid with a precomputed jump table
+//
+// jump[d][i] is the index of the cell the guard stops on when walking in
+// direction d from cell i, i.e. the cell right before the next obstacle, or
+// offMap if there is no obstacle ahead. With it the guard covers a whole
+// straight stretch of its patrol in one lookup.
+type patrolMap struct {
+	width, height int
+	blocked       []bool
+	jump          [numDirs][]int32
+}
+
+// patrolResult summarises a patrol from the guard's starting position
+type patrolResult struct {
+	steps    int    // Number of moves made before leaving the map
+	visited  []bool // Cells visited, indexed like patrolMap.blocked
+	distinct int    // Number of distinct cells visited
+}
+
+// newPatrolMap builds the jump table for a grid
+func newPatrolMap(cells [][]cell) *patrolMap {
+	pm := &patrolMap{height: len(cells)}
+	if pm.height > 0 {
+		pm.width = len(cells[0])
+	}
+	pm.blocked = make([]bool, pm.width*pm.height)
+	for y, row := range cells {
+		for x, c := range row {
+			pm.blocked[pm.index(x, y)] = c.val == blockedRune
+		}
+	}
+
+	// Walk backwards from the far edge in each direction, remembering the last open cell before an obstacle
+	for d := Dir(0); d < numDirs; d++ {
+		jump := make([]int32, len(pm.blocked))
+		m := moves[d]
+		for i := range jump {
+			x, y := i%pm.width, i/pm.width
+			// Start each line from the cell furthest along in direction d
+			if pm.contains(x+m.dx, y+m.dy) {
+				continue
+			}
+			stop := offMap
+			for pm.contains(x, y) {
+				j := pm.index(x, y)
+				if pm.blocked[j] {
+					stop = offMap // Obstacle cells are never stood on
+					jump[j] = offMap
+					// The cell before an obstacle is where the guard stops
+					if px, py := x-m.dx, y-m.dy; pm.contains(px, py) {
+						stop = int32(pm.index(px, py))
+					}
+				} else {
+					jump[j] = stop
+				}
+				x, y = x-m.dx, y-m.dy
+			}
+		}
+		pm.jump[d] = jump
+	}
+	return pm
+}
+
+// index returns the flattened index of a cell
+func (pm *patrolMap) index(x, y int) int {
+	return y*pm.width + x
+}
+
+// contains checks if the coordinates are on the map
+func (pm *patrolMap) contains(x, y int) bool {
+	return x >= 0 && x < pm.width && y >= 0 && y < pm.height
+}
+
+// patrol walks the guard from its start until it leaves the map, recording the cells it visits
+func (pm *patrolMap) patrol(start cell, d Dir) patrolResult {
+	res := patrolResult{visited: make([]bool, len(pm.blocked))}
+	pos := pm.index(start.x, start.y)
+	res.visited[pos] = true
+	res.distinct = 1
+
+	// A patrol that turns more times than there are cells and directions must be looping
+	for turns := 0; turns <= 4*len(pm.blocked); turns++ {
+		stop := pm.jump[d][pos]
+		m := moves[d]
+		step := m.dy*pm.width + m.dx
+
+		// Mark the stretch up to the stop, or up to the edge of the map
+		x, y := pos%pm.width, pos/pm.width
+		for {
+			if int32(pos) == stop {
+				break
+			}
+			x, y = x+m.dx, y+m.dy
+			if !pm.contains(x, y) {
+				return res
+			}
+			pos += step
+			res.steps++
+			if !res.visited[pos] {
+				res.visited[pos] = true
+				res.distinct++
+			}
+		}
+		d = d.turnRight()
+	}
+	return res
+}
+
+// loops checks whether the guard gets stuck in a loop if an extra obstacle is placed at index obstacle
+// seen must hold one entry per cell and direction; entries equal to stamp are treated as visited, so
+// the same slice can be reused across calls with increasing stamps
+func (pm *patrolMap) loops(start cell, d Dir, obstacle int, seen []int32, stamp int32) bool {
+	pos := pm.index(start.x, start.y)
+	ox, oy := obstacle%pm.width, obstacle/pm.width
+
+	for {
+		// Turning at the same cell in the same direction twice means the guard is going round in circles
+		state := pos*int(numDirs) + int(d)
+		if seen[state] == stamp {
+			return true
+		}
+		seen[state] = stamp
+
+		stop := pm.jump[d][pos]
+
+		// The extra obstacle cuts the stretch short if it lies between here and the stop
+		x, y := pos%pm.width, pos/pm.width
+		if pm.ahead(x, y, d, ox, oy, stop) {
+			m := moves[d]
+			stop = int32(pm.index(ox-m.dx, oy-m.dy))
+		}
+
+		if stop == offMap {
+			return false
+		}
+		pos = int(stop)
+		d = d.turnRight()
+	}
+}
+
+// ahead checks whether (ox, oy) is in front of (x, y) in direction d and no further than stop
+func (pm *patrolMap) ahead(x, y int, d Dir, ox, oy int, stop int32) bool {
+	// Distance from (x, y) to the obstacle along d, which must be on the same line
+	var dist int
+	switch d {
+	case up:
+		dist = y - oy
+		if ox != x {
+			return false
+		}
+	case down:
+		dist = oy - y
+		if ox != x {
+			return false
+		}
+	case left:
+		dist = x - ox
+		if oy != y {
+			return false
+		}
+	case right:
+		dist = ox - x
+		if oy != y {
+			return false
+		}
+	}
+	if dist <= 0 {
+		return false
+	}
+	if stop == offMap {
+		return true
+	}
+
+	// The existing stop is one cell before an obstacle, the new one must come no later than that obstacle
+	sx, sy := int(stop)%pm.width, int(stop)/pm.width
+	stopDist := abs(sx-x) + abs(sy-y)
+	return dist <= stopDist+1
+}
+
+// loopObstructions returns the cells where a single new obstacle traps the guard in a loop
+// Only cells on the guard's original route can change its patrol, and its start cell is off limits
+func (pm *patrolMap) loopObstructions(start cell, d Dir, route []bool) [][2]int {
+	seen := make([]int32, len(pm.blocked)*int(numDirs))
+	startIdx := pm.index(start.x, start.y)
+
+	loops := make([][2]int, 0)
+	var stamp int32
+	for i, onRoute := range route {
+		if !onRoute || i == startIdx || pm.blocked[i] {
+			continue
+		}
+		stamp++
+		if pm.loops(start, d, i, seen, stamp) {
+			loops = append(loops, [2]int{i % pm.width, i / pm.width})
+		}
+	}
+	return loops
+}
+
+// abs returns the absolute value of an integer
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
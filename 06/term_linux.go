@@ -0,0 +1,9 @@
+package main
+
+import "syscall"
+
+// ioctl requests reading and writing the terminal settings
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
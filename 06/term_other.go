@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import "errors"
+
+// errNoTerminal is returned where the viewer can't switch the terminal to raw mode
+var errNoTerminal = errors.New("the viewer needs a Unix terminal")
+
+// rawMode switches the terminal to read single key presses without echoing them
+func rawMode() (func(), error) {
+	return nil, errNoTerminal
+}
+
+// terminalSize returns the number of rows and columns of the terminal
+func terminalSize() (int, int, error) {
+	return 0, 0, errNoTerminal
+}
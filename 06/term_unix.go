@@ -0,0 +1,53 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// rawMode switches the terminal to read single key presses without echoing them
+// Returns a function that restores the previous terminal settings
+func rawMode() (func(), error) {
+	fd := os.Stdin.Fd()
+	var old syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, unsafe.Pointer(&old)); err != nil {
+		return nil, fmt.Errorf("error reading terminal settings, the viewer needs a terminal: %w", err)
+	}
+
+	// The same settings as cfmakeraw
+	raw := old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, fmt.Errorf("error switching terminal to raw mode: %w", err)
+	}
+	return func() {
+		ioctl(fd, ioctlSetTermios, unsafe.Pointer(&old))
+	}, nil
+}
+
+// terminalSize returns the number of rows and columns of the terminal
+func terminalSize() (int, int, error) {
+	var ws struct{ rows, cols, xpixel, ypixel uint16 }
+	if err := ioctl(os.Stdin.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, fmt.Errorf("error reading terminal size: %w", err)
+	}
+	return int(ws.rows), int(ws.cols), nil
+}
+
+// ioctl runs the ioctl request req against the file descriptor fd
+func ioctl(fd, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Runes drawn by the viewer
+const (
+	obstructionRune rune = 'O' // Obstruction placed from the viewer
+)
+
+// Arrow drawn for each direction the guard can face
+var dirArrows = [numDirs]rune{
+	up:    '^',
+	right: '>',
+	down:  'v',
+	left:  '<',
+}
+
+// ANSI escape codes used by the viewer
+const (
+	ansiClear      string = "\x1b[H\x1b[2J"
+	ansiHideCursor string = "\x1b[?25l"
+	ansiShowCursor string = "\x1b[?25h"
+	ansiReset      string = "\x1b[0m"
+	ansiDim        string = "\x1b[2m"
+	ansiGuard      string = "\x1b[1;33m" // Yellow
+	ansiTrail      string = "\x1b[36m"   // Cyan
+	ansiPlaced     string = "\x1b[1;31m" // Red
+)
+
+// errLoop ends a timeline whose guard is back in a position and direction it has already been in
+var errLoop = errors.New("guard is stuck in a loop")
+
+// timeline is every state the guard passes through, one per call to step
+type timeline struct {
+	frames []guard
+	end    error // Why the patrol ended: errOffMap, errBoxedIn or errLoop
+}
+
+// simulate records the guard's patrol from g one step at a time
+func simulate(cells [][]cell, g guard) timeline {
+	tl := timeline{frames: []guard{g}}
+	seen := map[[3]int]bool{{g.currPos.x, g.currPos.y, int(g.direction)}: true}
+
+	for {
+		var err error
+		g, cells, err = step(g, cells)
+		if err != nil {
+			tl.end = err
+			return tl
+		}
+		tl.frames = append(tl.frames, g)
+
+		state := [3]int{g.currPos.x, g.currPos.y, int(g.direction)}
+		if seen[state] {
+			tl.end = errLoop
+			return tl
+		}
+		seen[state] = true
+	}
+}
+
+// viewer is an interactive terminal viewer stepping through a patrol
+type viewer struct {
+	cells    [][]cell // Map as read, before any steps
+	original timeline // Patrol on the map as read
+	tl       timeline // Patrol being viewed, including any placed obstruction
+	frame    int      // Index of the frame being shown
+
+	placed      bool // Whether an obstruction has been placed
+	placedX     int
+	placedY     int
+	placedFrame int // Frame the obstruction was placed in front of the guard
+
+	playing bool
+	speed   int // Frames per second while playing
+
+	rows, cols int // Size of the terminal
+}
+
+// newViewer creates a viewer for the guard's patrol over the map
+func newViewer(cells [][]cell, g guard, speed int) *viewer {
+	v := &viewer{cells: cells, speed: max(speed, 1), rows: 40, cols: 120}
+	v.original = simulate(copyCells(cells), g)
+	v.tl = v.original
+	return v
+}
+
+// run shows the viewer until the user quits
+func (v *viewer) run() error {
+	restore, err := rawMode()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	if rows, cols, err := terminalSize(); err == nil {
+		v.rows, v.cols = rows, cols
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	fmt.Fprint(out, ansiHideCursor)
+	defer func() {
+		fmt.Fprint(out, ansiShowCursor)
+		out.Flush()
+	}()
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+
+	ticker := time.NewTicker(time.Second / time.Duration(v.speed))
+	defer ticker.Stop()
+
+	for {
+		v.render(out)
+		if err := out.Flush(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+			if !v.playing {
+				continue
+			}
+			if !v.forward() {
+				v.playing = false
+			}
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			switch key {
+			case "q", "\x03": // q or Ctrl-C
+				return nil
+			case " ":
+				v.playing = !v.playing
+			case "n", "l", "right":
+				v.forward()
+			case "b", "h", "left":
+				v.back()
+			case "t":
+				v.nextTurn()
+			case "T":
+				v.prevTurn()
+			case "g":
+				v.frame = 0
+			case "G":
+				v.frame = len(v.tl.frames) - 1
+			case "+", "=":
+				v.speed = min(v.speed*2, 1000)
+				ticker.Reset(time.Second / time.Duration(v.speed))
+			case "-":
+				v.speed = max(v.speed/2, 1)
+				ticker.Reset(time.Second / time.Duration(v.speed))
+			case "o":
+				v.toggleObstruction()
+			}
+		}
+	}
+}
+
+// forward moves to the next frame, returns false at the end of the patrol
+func (v *viewer) forward() bool {
+	if v.frame+1 >= len(v.tl.frames) {
+		return false
+	}
+	v.frame++
+	return true
+}
+
+// back moves to the previous frame
+func (v *viewer) back() {
+	if v.frame > 0 {
+		v.frame--
+	}
+}
+
+// nextTurn moves forward to the next frame where the guard faces a new direction
+func (v *viewer) nextTurn() {
+	dir := v.tl.frames[v.frame].direction
+	for v.forward() {
+		if v.tl.frames[v.frame].direction != dir {
+			return
+		}
+	}
+}
+
+// prevTurn moves back to the last frame where the guard faces a new direction
+func (v *viewer) prevTurn() {
+	for v.frame > 0 {
+		v.frame--
+		if v.frame > 0 && v.tl.frames[v.frame].direction != v.tl.frames[v.frame-1].direction {
+			return
+		}
+	}
+}
+
+// toggleObstruction places an obstruction in front of the guard, or removes the one already placed
+// The patrol is re-run from the current frame so loops show up straight away
+func (v *viewer) toggleObstruction() {
+	if v.placed {
+		v.placed = false
+		v.tl = v.original
+		v.frame = min(v.frame, len(v.tl.frames)-1)
+		return
+	}
+
+	g := v.tl.frames[v.frame]
+	m := moves[g.direction]
+	x, y := g.currPos.x+m.dx, g.currPos.y+m.dy
+	start := v.original.frames[0].currPos
+	if !inBounds(x, y, v.cells) || v.cells[y][x].val == blockedRune || (x == start.x && y == start.y) {
+		return
+	}
+
+	// Replay the patrol up to here on a copy of the map with the obstruction added
+	cells := copyCells(v.cells)
+	for _, c := range g.path {
+		cells[c.y][c.x].val = visitedRune
+	}
+	cells[y][x].val = blockedRune
+
+	// Copy the path so the original timeline's frames aren't overwritten as the new one grows
+	g.path = append([]cell{}, g.path...)
+	branch := simulate(cells, g)
+
+	v.tl = timeline{
+		frames: append(append([]guard{}, v.original.frames[:v.frame]...), branch.frames...),
+		end:    branch.end,
+	}
+	v.placed, v.placedX, v.placedY, v.placedFrame = true, x, y, v.frame
+}
+
+// render draws the part of the map around the guard and a status line
+func (v *viewer) render(w *bufio.Writer) {
+	g := v.tl.frames[v.frame]
+
+	// Cells visited so far
+	visited := make(map[[2]int]bool)
+	for _, c := range g.path {
+		visited[[2]int{c.x, c.y}] = true
+	}
+
+	// Keep the guard in the middle of the window when the map doesn't fit
+	height, width := len(v.cells), len(v.cells[0])
+	viewRows, viewCols := max(v.rows-4, 1), max(v.cols, 1)
+	top := clamp(g.currPos.y-viewRows/2, 0, max(height-viewRows, 0))
+	left := clamp(g.currPos.x-viewCols/2, 0, max(width-viewCols, 0))
+
+	fmt.Fprint(w, ansiClear)
+	for y := top; y < min(top+viewRows, height); y++ {
+		for x := left; x < min(left+viewCols, width); x++ {
+			switch {
+			case x == g.currPos.x && y == g.currPos.y:
+				fmt.Fprintf(w, "%s%c%s", ansiGuard, dirArrows[g.direction], ansiReset)
+			case v.placed && x == v.placedX && y == v.placedY:
+				fmt.Fprintf(w, "%s%c%s", ansiPlaced, obstructionRune, ansiReset)
+			case v.cells[y][x].val == blockedRune:
+				w.WriteRune(blockedRune)
+			case visited[[2]int{x, y}]:
+				fmt.Fprintf(w, "%s%c%s", ansiTrail, visitedRune, ansiReset)
+			default:
+				fmt.Fprintf(w, "%s%c%s", ansiDim, openRune, ansiReset)
+			}
+		}
+		w.WriteString("\r\n")
+	}
+
+	// Status
+	state := "paused"
+	if v.playing {
+		state = "playing"
+	}
+	fmt.Fprintf(w, "\r\nstep %d/%d  pos (%d,%d) facing %s  %s at %d steps/s  distinct %d",
+		v.frame, len(v.tl.frames)-1, g.currPos.x, g.currPos.y, g.direction, state, v.speed, len(visited))
+	if v.frame == len(v.tl.frames)-1 {
+		fmt.Fprintf(w, "  [%s]", v.tl.end)
+	}
+	if v.placed {
+		fmt.Fprintf(w, "  obstruction at (%d,%d) from step %d", v.placedX, v.placedY, v.placedFrame)
+		if errors.Is(v.tl.end, errLoop) || errors.Is(v.tl.end, errBoxedIn) {
+			fmt.Fprintf(w, " %sLOOP%s", ansiPlaced, ansiReset)
+		}
+	}
+	w.WriteString("\r\n" + ansiDim +
+		"space play/pause  n/b step  t/T next/prev turn  g/G start/end  +/- speed  o obstruction  q quit" +
+		ansiReset)
+}
+
+// readKeys sends each key read from r to keys, with arrow keys named "left" and "right"
+// Escape sequences are read a byte at a time until they're complete, so a sequence split across reads isn't lost
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	in := bufio.NewReader(r)
+	for {
+		b, err := in.ReadByte()
+		if err != nil {
+			return
+		}
+		if b != 0x1b {
+			keys <- string(b)
+			continue
+		}
+
+		// Escape sequence, ESC [ C or ESC O C for the right arrow
+		b, err = in.ReadByte()
+		if err != nil {
+			return
+		}
+		var final byte
+		switch b {
+		case '[':
+			// Control sequence: parameters, e.g. 1;5 for Ctrl, up to a final byte from @ to ~
+			for final < '@' || final > '~' {
+				if final, err = in.ReadByte(); err != nil {
+					return
+				}
+			}
+		case 'O':
+			if final, err = in.ReadByte(); err != nil {
+				return
+			}
+		default:
+			// A lone escape, the next key is read as usual
+			in.UnreadByte()
+			continue
+		}
+		switch final {
+		case 'C':
+			keys <- "right"
+		case 'D':
+			keys <- "left"
+		}
+	}
+}
+
+// copyCells returns a copy of the grid that can be modified independently
+func copyCells(cells [][]cell) [][]cell {
+	cp := make([][]cell, len(cells))
+	for y, row := range cells {
+		cp[y] = append([]cell{}, row...)
+	}
+	return cp
+}
+
+// clamp limits v to the range [lo, hi]
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
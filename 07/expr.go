@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/digits"
+)
+
+// evalOrder is the grouping rule used to turn values and operators into an expression tree
+type evalOrder uint8
+
+const (
+	orderLeftToRight           evalOrder = iota // Strictly left to right, the puzzle's rules
+	orderPrecedenceConcatTight                  // * before +, with || binding tightest of all
+	orderPrecedenceConcatLoose                  // * before +, with || binding loosest of all
+	orderRightToLeft                            // Strictly right to left
+)
+
+// Maps order names, as given on the command line, to orders
+var evalOrders = map[string]evalOrder{
+	"left-to-right":           orderLeftToRight,
+	"precedence-concat-tight": orderPrecedenceConcatTight,
+	"precedence-concat-loose": orderPrecedenceConcatLoose,
+	"right-to-left":           orderRightToLeft,
+}
+
+// Returns the name of the order
+func (o evalOrder) String() string {
+	for name, order := range evalOrders {
+		if order == o {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// parseEvalOrder returns the order with the given name
+func parseEvalOrder(name string) (evalOrder, error) {
+	o, ok := evalOrders[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown evaluation order %q, expected left-to-right, right-to-left, precedence-concat-tight or precedence-concat-loose", name)
+	}
+	return o, nil
+}
+
+// node is an expression tree, either a value or an operator applied to two subtrees
+type node struct {
+	op          string // Operator, empty for a value
+	val         int64  // Value of a leaf
+	left, right *node
+}
+
+// buildTree groups the values and the operators between them according to the order
+// There must be one operator fewer than there are values
+func buildTree(vals []int64, operators []string, order evalOrder) *node {
+	return new(treeBuilder).build(vals, operators, order)
+}
+
+// treeBuilder builds expression trees, reusing its storage from one tree to the next
+// A tree it returns is only valid until the next call to build
+type treeBuilder struct {
+	nodes    []node
+	operands []*node
+	pending  []string
+}
+
+// build groups the values and the operators between them according to the order
+func (b *treeBuilder) build(vals []int64, operators []string, order evalOrder) *node {
+	b.nodes = b.nodes[:0]
+	b.operands = b.operands[:0]
+	b.pending = b.pending[:0]
+
+	switch order {
+	case orderRightToLeft:
+		root := b.leaf(vals[len(vals)-1])
+		for i := len(operators) - 1; i >= 0; i-- {
+			root = b.apply(operators[i], b.leaf(vals[i]), root)
+		}
+		return root
+	case orderPrecedenceConcatTight, orderPrecedenceConcatLoose:
+		return b.buildPrecedence(vals, operators, order)
+	default:
+		root := b.leaf(vals[0])
+		for i, op := range operators {
+			root = b.apply(op, root, b.leaf(vals[i+1]))
+		}
+		return root
+	}
+}
+
+// buildPrecedence groups the values with the operator precedence parsing algorithm
+// Operators of equal precedence group left to right
+func (b *treeBuilder) buildPrecedence(vals []int64, operators []string, order evalOrder) *node {
+	b.operands = append(b.operands, b.leaf(vals[0]))
+
+	// reduce combines the top two operands with the most recent pending operator
+	reduce := func() {
+		op := b.pending[len(b.pending)-1]
+		b.pending = b.pending[:len(b.pending)-1]
+		right, left := b.operands[len(b.operands)-1], b.operands[len(b.operands)-2]
+		b.operands = append(b.operands[:len(b.operands)-2], b.apply(op, left, right))
+	}
+
+	for i, op := range operators {
+		for len(b.pending) > 0 && precedence(b.pending[len(b.pending)-1], order) >= precedence(op, order) {
+			reduce()
+		}
+		b.pending = append(b.pending, op)
+		b.operands = append(b.operands, b.leaf(vals[i+1]))
+	}
+	for len(b.pending) > 0 {
+		reduce()
+	}
+	return b.operands[0]
+}
+
+// leaf adds a value to the tree
+func (b *treeBuilder) leaf(v int64) *node {
+	return b.add(node{val: v})
+}
+
+// apply adds an operator applied to two subtrees to the tree
+func (b *treeBuilder) apply(op string, left, right *node) *node {
+	return b.add(node{op: op, left: left, right: right})
+}
+
+// add stores a node, growing the storage in chunks so pointers to earlier nodes stay valid
+func (b *treeBuilder) add(n node) *node {
+	if len(b.nodes) == cap(b.nodes) {
+		// Nodes of a finished tree may still be referenced, so start a fresh chunk instead of reallocating
+		b.nodes = make([]node, 0, max(2*cap(b.nodes), 32))
+	}
+	b.nodes = append(b.nodes, n)
+	return &b.nodes[len(b.nodes)-1]
+}
+
+// precedence returns the binding strength of an operator under a precedence order, higher binds tighter
+func precedence(op string, order evalOrder) int {
+	if order == orderPrecedenceConcatTight {
+		switch op {
+		case concatOperator:
+			return 3
+		case multOperator:
+			return 2
+		}
+		return 1
+	}
+	switch op {
+	case multOperator:
+		return 3
+	case addOperator:
+		return 2
+	}
+	return 1
+}
+
+// eval evaluates the tree, returning false if any step overflows an int64
+func (n *node) eval() (int64, bool) {
+	if n.op == "" {
+		return n.val, true
+	}
+	a, ok := n.left.eval()
+	if !ok {
+		return 0, false
+	}
+	b, ok := n.right.eval()
+	if !ok {
+		return 0, false
+	}
+	return doChecked(n.op, a, b)
+}
+
+// String renders the tree with every operation below the root in parentheses, e.g. 81 + (40 * 27)
+func (n *node) String() string {
+	if n.op == "" {
+		return strconv.FormatInt(n.val, 10)
+	}
+	return n.left.grouped() + " " + n.op + " " + n.right.grouped()
+}
+
+// grouped renders a subtree, in parentheses unless it is a single value
+func (n *node) grouped() string {
+	if n.op == "" {
+		return n.String()
+	}
+	return "(" + n.String() + ")"
+}
+
+// doChecked performs the operation on non-negative values, returning false if the result overflows an int64
+func doChecked(operator string, a, b int64) (int64, bool) {
+	switch operator {
+	case multOperator:
+		return digits.Mul(a, b)
+	case addOperator:
+		if a > math.MaxInt64-b {
+			return 0, false
+		}
+		return a + b, true
+	case concatOperator:
+		// Shift a left by as many decimal digits as b has
+		count := digits.Count(b)
+		if count > 18 {
+			return 0, false
+		}
+		shifted, ok := digits.Mul(a, digits.Pow10(count))
+		if !ok || shifted > math.MaxInt64-b {
+			return 0, false
+		}
+		return shifted + b, true
+	}
+	return 0, false
+}
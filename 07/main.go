@@ -1,11 +1,18 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"strconv"
-	"strings"
+	"os/signal"
+	"runtime"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 7: Challenge
@@ -25,31 +32,67 @@ var (
 )
 
 type equation struct {
+	line   int     // 1-based line of the equation in the input
 	answer int64   // Target value of the equation
 	vals   []int64 // List of values in the equation
 }
 
 func main() {
-	eqs, err := readInput(filename)
+	report := flag.String("report", "", "report every equation as text or json instead of printing only the sum")
+	orderName := flag.String("order", "left-to-right", "evaluation order: left-to-right, right-to-left, precedence-concat-tight or precedence-concat-loose")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of equations solved at the same time")
+	timeout := flag.Duration("timeout", 0, "give up solving after this long, 0 for no limit")
+	run := result.Main(7, filename)
+
+	order, err := parseEvalOrder(*orderName)
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("parsing evaluation order", "err", err)
 		os.Exit(1)
 	}
 
-	var sum int64
-	for _, eq := range eqs {
-		// Generate all possible combinations of operators for the given equation
-		opCombos := generateCombinations(ops, len(eq.vals)-1)
-		// Solve the equations and calculate the total sum of valid answers
-		sum += doMath(opCombos, eq)
+	eqs, err := readInput(filename)
+	if err != nil {
+		run.Fatal(err)
+	}
+	slog.Debug("read equations", "count", len(eqs), "order", order, "workers", *workers)
+
+	// Ctrl-C or the timeout stop every worker
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	// Report every equation instead of the run's result
+	if *report != "" {
+		sols, err := solveAll(ctx, eqs, ops, order, *workers)
+		if err != nil {
+			slog.Error("solving equations", "err", err)
+			os.Exit(1)
+		}
+		if err := writeReport(os.Stdout, sols, *report); err != nil {
+			slog.Error("writing report", "err", err)
+			os.Exit(1)
+		}
+		return
 	}
-	fmt.Println(sum) // Print the total sum of valid answers
+
+	// Part 2: Try every combination of operators, concatenation included, for each equation
+	run.Part(2, func() (any, error) {
+		sols, err := solveAll(ctx, eqs, ops, order, *workers)
+		if err != nil {
+			return nil, err
+		}
+		return sumSolvable(sols), nil
+	})
+
+	run.Finish()
 }
 
 // Reads the input file and parses it into equations
 func readInput(fname string) ([]equation, error) {
-	var export []equation // List to store the parsed equations
-
 	// Open the input file
 	f, err := os.Open(fname)
 	if err != nil {
@@ -57,130 +100,66 @@ func readInput(fname string) ([]equation, error) {
 	}
 	defer f.Close() // Ensure the file is closed after the function completes
 
-	scanner := bufio.NewScanner(f)
-	var l []byte
-	var eq equation
-	for scanner.Scan() {
-		l = scanner.Bytes() // Read the line as a byte slice
-		line := strings.Split(string(l), delimColon)
-		ans, err := strconv.Atoi(line[0]) // Parse the target value
-		if err != nil {
-			return nil, fmt.Errorf("error parsing file [%s]: expected int for first value: %w", fname, err)
-		}
-		if len(line) != 2 {
-			return nil, fmt.Errorf("error unexpected format: %s", line)
-		}
-
-		// Parse the list of values
-		vsi := make([]int64, 0)
-		vs := strings.Split(line[1], delimSpace)
-		for _, v := range vs {
-			if v == "" {
-				continue // Skip empty values
-			}
-			vi, err := strconv.Atoi(v)
-			if err != nil {
-				return nil, fmt.Errorf("expected int64 [%s]: %w", v, err)
-			}
-			vsi = append(vsi, int64(vi))
-		}
-
-		// Create an equation object
-		eq = equation{
-			answer: int64(ans),
-			vals:   vsi,
-		}
-		export = append(export, eq) // Add the equation to the list
+	eqs, err := parseEquations(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
 	}
+	return eqs, nil
+}
 
-	// Check for any errors encountered during scanning
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file [%s]: %w", fname, err)
+// Parses equations, one per line
+func parseEquations(r io.Reader) ([]equation, error) {
+	eqs := input.NewSection("equations", parseEquation)
+	if err := input.ReadSections(r, eqs); err != nil {
+		return nil, err
 	}
-
-	return export, nil
+	for i := range eqs.Values {
+		eqs.Values[i].line = eqs.Lines[i]
+	}
+	return eqs.Values, nil
 }
 
-// Generates all combinations of operators for the given length
-func generateCombinations(elements []string, length int) [][]string {
-	var result [][]string // List to store the combinations
-
-	combination := make([]int64, length) // Array to track the current combination indices
-
-	for {
-		// Create the current combination based on indices
-		current := make([]string, length)
-		for i := 0; i < length; i++ {
-			current[i] = elements[combination[i]]
-		}
-		result = append(result, current) // Add the combination to the result
-
-		// Increment the combination indices
-		idx := length - 1
-		for idx >= 0 {
-			combination[idx]++
-			if int(combination[idx]) < len(elements) {
-				break
-			}
-			combination[idx] = 0
-			idx--
-		}
-
-		// If we are done, break the loop
-		if idx < 0 {
-			break
-		}
+// Parses an equation (e.g., "190: 10 19")
+func parseEquation(line string) (equation, error) {
+	// Check the format before reading either side
+	parts := input.Split(line, delimColon)
+	if len(parts) != 2 {
+		return equation{}, fmt.Errorf("expected an answer and values separated by %q", delimColon)
 	}
 
-	return result
-}
+	ans, err := parseNonNegative(parts[0])
+	if err != nil {
+		return equation{}, err
+	}
 
-// Solves the equations by trying all operator combinations and returns the total sum of valid answers
-func doMath(operators [][]string, equation equation) int64 {
-	var totalSum int64
-
-	for _, operatorCombo := range operators {
-		var ans int64
-		for i := 0; i < len(equation.vals)-1; i++ {
-			if i == len(operatorCombo) {
-				break // Avoid index out of range
-			}
-			if i == 0 {
-				ans = equation.vals[i] // Initialize the result with the first value
-			}
-			newAns := do(operatorCombo[i], ans, equation.vals[i+1]) // Apply the operator
-			ans = newAns
+	// Parse the list of values
+	vals := make([]int64, 0)
+	for _, v := range input.Split(parts[1].Text, delimSpace) {
+		if v.Text == "" {
+			continue // Skip empty values
 		}
-		if ans == equation.answer { // Check if the result matches the target
-			totalSum = totalSum + equation.answer
-			break // Skip further operator combinations for this equation
+		v.Column += parts[1].Column - 1 // Column in the line rather than after the colon
+		val, err := parseNonNegative(v)
+		if err != nil {
+			return equation{}, err
 		}
+		vals = append(vals, val)
 	}
-
-	return totalSum
-}
-
-// Performs the mathematical operation based on the given operator
-func do(operator string, a, b int64) int64 {
-	switch operator {
-	case multOperator:
-		return a * b
-	case addOperator:
-		return a + b
-	case concatOperator:
-		return concat(a, b)
+	if len(vals) == 0 {
+		return equation{}, input.At(parts[1].Column, errors.New("expected at least one value"))
 	}
-	os.Exit(1) // Exit if an unsupported operator is encountered
-	return 0
+
+	return equation{answer: ans, vals: vals}, nil
 }
 
-// concat returns a,b => ab as the literal decimal values concatenated
-// e.g. 12 || 34 => 1234
-func concat(a, b int64) int64 {
-	cs := fmt.Sprintf("%d%d", a, b)
-	export, err := strconv.ParseInt(cs, 10, 64)
+// Parses a field holding a non-negative integer
+func parseNonNegative(f input.Field) (int64, error) {
+	v, err := f.Int()
 	if err != nil {
-		os.Exit(1)
+		return 0, err
+	}
+	if v < 0 {
+		return 0, input.At(f.Column, fmt.Errorf("expected a non-negative integer, found %d", v))
 	}
-	return export
+	return int64(v), nil
 }
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+const example = `190: 10 19
+3267: 81 40 27
+83: 17 5
+156: 15 6
+7290: 6 8 6 15
+161011: 16 10 13
+192: 17 8 14
+21037: 9 7 18 13
+292: 11 6 16 20
+`
+
+// parseOne parses a single equation or fails the test
+func parseOne(t *testing.T, line string) equation {
+	t.Helper()
+	eq, err := parseEquation(line)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", line, err)
+	}
+	return eq
+}
+
+func TestExample(t *testing.T) {
+	eqs, err := parseEquations(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		operators []string
+		want      int64
+	}{
+		{name: "part 1", operators: []string{multOperator, addOperator}, want: 3749},
+		{name: "part 2", operators: ops, want: 11387},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sols, err := solveAll(context.Background(), eqs, tt.operators, orderLeftToRight, 4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sumSolvable(sols); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []struct {
+		line        string
+		assignments int
+		expression  string
+	}{
+		{line: "190: 10 19", assignments: 1, expression: "10 * 19 = 190"},
+		{line: "3267: 81 40 27", assignments: 2, expression: "81 * 40 + 27 = 3267"},
+		{line: "156: 15 6", assignments: 1, expression: "15 || 6 = 156"},
+		{line: "7290: 6 8 6 15", assignments: 1, expression: "6 * 8 || 6 * 15 = 7290"},
+		{line: "192: 17 8 14", assignments: 1, expression: "17 || 8 + 14 = 192"},
+		{line: "292: 11 6 16 20", assignments: 1, expression: "11 + 6 * 16 + 20 = 292"},
+		// Every operator gives 4, the first one tried is shown
+		{line: "4: 2 2", assignments: 2, expression: "2 * 2 = 4"},
+		{line: "22: 2 2", assignments: 1, expression: "2 || 2 = 22"},
+		// A single value is its own expression
+		{line: "5: 5", assignments: 1, expression: "5 = 5"},
+		// Unsolvable equations are shown as they were written
+		{line: "83: 17 5", assignments: 0, expression: "83: 17 5"},
+		{line: "161011: 16 10 13", assignments: 0, expression: "161011: 16 10 13"},
+		// Results that overflow are abandoned rather than wrapping round
+		{line: "9223372036854775807: 9223372036854775807 1", assignments: 1, expression: "9223372036854775807 * 1 = 9223372036854775807"},
+		{line: "1: 9223372036854775807 2 0", assignments: 0, expression: "1: 9223372036854775807 2 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			sol, err := solve(context.Background(), parseOne(t, tt.line), ops, orderLeftToRight)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sol.assignments != tt.assignments {
+				t.Errorf("got %d assignments, want %d", sol.assignments, tt.assignments)
+			}
+			if sol.solvable() != (tt.assignments > 0) {
+				t.Errorf("got solvable %t with %d assignments", sol.solvable(), tt.assignments)
+			}
+			if got := sol.expression(); got != tt.expression {
+				t.Errorf("got expression %q, want %q", got, tt.expression)
+			}
+		})
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	eqs, err := parseEquations(strings.NewReader("190: 10 19\n83: 17 5\n4: 2 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sols, err := solveAll(context.Background(), eqs, ops, orderLeftToRight, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeReport(&buf, sols, reportText); err != nil {
+			t.Fatal(err)
+		}
+		want := "line 1: solvable (1 assignment): 10 * 19 = 190\n" +
+			"line 2: unsolvable: 83: 17 5\n" +
+			"line 3: solvable (2 assignments): 2 * 2 = 4\n" +
+			"solvable: 2/3, sum: 194\n"
+		if got := buf.String(); got != want {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeReport(&buf, sols, reportJSON); err != nil {
+			t.Fatal(err)
+		}
+		dec := json.NewDecoder(&buf)
+		var recs []solutionRecord
+		for dec.More() {
+			var rec solutionRecord
+			if err := dec.Decode(&rec); err != nil {
+				t.Fatal(err)
+			}
+			recs = append(recs, rec)
+		}
+		if len(recs) != 3 {
+			t.Fatalf("got %d records, want 3", len(recs))
+		}
+		if r := recs[1]; r.Line != 2 || r.Solvable || r.Expression != "" || r.Assignments != 0 {
+			t.Errorf("unsolvable record %+v", r)
+		}
+		if r := recs[2]; r.Line != 3 || !r.Solvable || r.Expression != "2 * 2 = 4" || r.Assignments != 2 || r.Order != "left-to-right" {
+			t.Errorf("solvable record %+v", r)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := writeReport(&bytes.Buffer{}, sols, "xml"); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestBuildTree(t *testing.T) {
+	tests := []struct {
+		name      string
+		vals      []int64
+		operators []string
+		want      map[evalOrder]string // Rendered tree and its value under each order
+	}{
+		{
+			name: "add then multiply", vals: []int64{2, 3, 4}, operators: []string{addOperator, multOperator},
+			want: map[evalOrder]string{
+				orderLeftToRight:           "(2 + 3) * 4 = 20",
+				orderRightToLeft:           "2 + (3 * 4) = 14",
+				orderPrecedenceConcatTight: "2 + (3 * 4) = 14",
+				orderPrecedenceConcatLoose: "2 + (3 * 4) = 14",
+			},
+		},
+		{
+			name: "concat then multiply", vals: []int64{1, 2, 3}, operators: []string{concatOperator, multOperator},
+			want: map[evalOrder]string{
+				orderLeftToRight:           "(1 || 2) * 3 = 36",
+				orderRightToLeft:           "1 || (2 * 3) = 16",
+				orderPrecedenceConcatTight: "(1 || 2) * 3 = 36",
+				orderPrecedenceConcatLoose: "1 || (2 * 3) = 16",
+			},
+		},
+		{
+			name: "every operator", vals: []int64{2, 3, 4, 5}, operators: []string{multOperator, addOperator, concatOperator},
+			want: map[evalOrder]string{
+				orderLeftToRight:           "((2 * 3) + 4) || 5 = 105",
+				orderRightToLeft:           "2 * (3 + (4 || 5)) = 96",
+				orderPrecedenceConcatTight: "(2 * 3) + (4 || 5) = 51",
+				orderPrecedenceConcatLoose: "((2 * 3) + 4) || 5 = 105",
+			},
+		},
+		{
+			// Operators of equal precedence group left to right
+			name: "equal precedence", vals: []int64{1, 2, 3}, operators: []string{addOperator, addOperator},
+			want: map[evalOrder]string{
+				orderLeftToRight:           "(1 + 2) + 3 = 6",
+				orderRightToLeft:           "1 + (2 + 3) = 6",
+				orderPrecedenceConcatTight: "(1 + 2) + 3 = 6",
+				orderPrecedenceConcatLoose: "(1 + 2) + 3 = 6",
+			},
+		},
+		{
+			name: "single value", vals: []int64{7}, operators: nil,
+			want: map[evalOrder]string{
+				orderLeftToRight:           "7 = 7",
+				orderRightToLeft:           "7 = 7",
+				orderPrecedenceConcatTight: "7 = 7",
+				orderPrecedenceConcatLoose: "7 = 7",
+			},
+		},
+	}
+	for _, tt := range tests {
+		for order, want := range tt.want {
+			t.Run(tt.name+"/"+order.String(), func(t *testing.T) {
+				tree := buildTree(tt.vals, tt.operators, order)
+				v, ok := tree.eval()
+				if !ok {
+					t.Fatalf("%s overflowed", tree)
+				}
+				if got := tree.String() + " = " + strconv.FormatInt(v, 10); got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestTreeOverflow(t *testing.T) {
+	for _, op := range ops {
+		tree := buildTree([]int64{math.MaxInt64, 2, 0}, []string{op, multOperator}, orderLeftToRight)
+		if v, ok := tree.eval(); ok {
+			t.Errorf("%s evaluated to %d, want an overflow", tree, v)
+		}
+	}
+}
+
+func TestParseEvalOrder(t *testing.T) {
+	for name, want := range evalOrders {
+		got, err := parseEvalOrder(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != want || got.String() != name {
+			t.Errorf("%s: got %s", name, got)
+		}
+	}
+	if _, err := parseEvalOrder("inside-out"); err == nil {
+		t.Error("expected an error for an unknown order")
+	}
+}
+
+// bruteForce counts the assignments whose tree gives the answer, without any pruning
+func bruteForce(eq equation, order evalOrder) int {
+	count := 0
+	chosen := make([]string, len(eq.vals)-1)
+	var try func(i int)
+	try = func(i int) {
+		if i == len(chosen) {
+			if v, ok := buildTree(eq.vals, chosen, order).eval(); ok && v == eq.answer {
+				count++
+			}
+			return
+		}
+		for _, op := range ops {
+			chosen[i] = op
+			try(i + 1)
+		}
+	}
+	try(0)
+	return count
+}
+
+func TestOrdersMatchBruteForce(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(example), "\n")
+	// Zeros and ones defeat pruning, so make sure the search still finds them
+	lines = append(lines, "0: 5 0 3 0", "10: 1 0 1 0", "6: 1 2 3", "15: 1 5 0 1 1", "105: 2 3 4 5")
+	for name, o := range evalOrders {
+		t.Run(name, func(t *testing.T) {
+			for _, line := range lines {
+				eq := parseOne(t, line)
+				sol, err := solve(context.Background(), eq, ops, o)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := bruteForce(eq, o); sol.assignments != want {
+					t.Errorf("%s: got %d assignments, want %d", line, sol.assignments, want)
+				}
+				if sol.solvable() {
+					if v, ok := buildTree(eq.vals, sol.operators, o).eval(); !ok || v != eq.answer {
+						t.Errorf("%s: reported %s, which gives %d", line, sol.expression(), v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGroupedExpression(t *testing.T) {
+	tests := []struct {
+		order evalOrder
+		line  string
+		want  string
+	}{
+		{order: orderLeftToRight, line: "292: 11 6 16 20", want: "11 + 6 * 16 + 20 = 292"},
+		{order: orderRightToLeft, line: "11326: 11 6 16 20", want: "11 || (6 + (16 * 20)) = 11326"},
+		{order: orderPrecedenceConcatTight, line: "436: 11 6 16 20", want: "(11 || 6) + (16 * 20) = 436"},
+		{order: orderPrecedenceConcatLoose, line: "17321: 11 6 16 20 1", want: "(11 + 6) || ((16 * 20) + 1) = 17321"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order.String(), func(t *testing.T) {
+			sol, err := solve(context.Background(), parseOne(t, tt.line), ops, tt.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sol.expression(); got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolveAllOrder(t *testing.T) {
+	eqs, err := parseEquations(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := solveAll(context.Background(), eqs, ops, orderLeftToRight, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sol := range want {
+		if sol.eq.line != i+1 {
+			t.Fatalf("solution %d is for line %d", i, sol.eq.line)
+		}
+	}
+	// However many workers share the equations, the solutions come back in input order
+	for _, workers := range []int{0, 2, 3, 8, 64} {
+		for range 10 {
+			got, err := solveAll(context.Background(), eqs, ops, orderLeftToRight, workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%d workers: solutions differ from one worker", workers)
+			}
+		}
+	}
+}
+
+func TestReportLines(t *testing.T) {
+	// Blank lines before the equations don't shift their line numbers
+	eqs, err := parseEquations(strings.NewReader("\n\n190: 10 19\r\n83: 17 5\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sols, err := solveAll(context.Background(), eqs, ops, orderLeftToRight, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writeReport(&buf, sols, reportText); err != nil {
+		t.Fatal(err)
+	}
+	want := "line 3: solvable (1 assignment): 10 * 19 = 190\n" +
+		"line 4: unsolvable: 83: 17 5\n" +
+		"solvable: 1/2, sum: 190\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSolveAllCancelled(t *testing.T) {
+	// Zeros can't be pruned, so this takes 3^29 steps unless the search is abandoned
+	endless := parseOne(t, "1:"+strings.Repeat(" 0", 30))
+
+	t.Run("before starting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		eqs := []equation{parseOne(t, "190: 10 19"), endless}
+		sols, err := solveAll(ctx, eqs, ops, orderLeftToRight, 2)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got error %v, want %v", err, context.Canceled)
+		}
+		if len(sols) != len(eqs) {
+			t.Fatalf("got %d solutions, want %d", len(sols), len(eqs))
+		}
+	})
+
+	for name, order := range evalOrders {
+		t.Run("during "+name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, err := solveAll(ctx, []equation{endless, endless}, ops, order, 2)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+			}
+			if !strings.Contains(err.Error(), "stopped after solving 0 of 2 equations") {
+				t.Errorf("got error %q", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took %s to stop", elapsed)
+			}
+		})
+	}
+}
+
+func FuzzParseEquations(f *testing.F) {
+	f.Add("190: 10 19\n3267: 81 40 27\n83: 17 5\n156: 15 6\n")
+	f.Add("190 10 19\n")
+	f.Add("190: \n")
+	f.Add("190: 10  -19\n")
+	f.Add("1: 2: 3\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		eqs, err := parseEquations(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		for _, eq := range eqs {
+			if eq.answer < 0 || len(eq.vals) == 0 {
+				t.Fatalf("parsed %+v", eq)
+			}
+			for _, v := range eq.vals {
+				if v < 0 {
+					t.Fatalf("parsed negative value in %+v", eq)
+				}
+			}
+		}
+	})
+}
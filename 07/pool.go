@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// solveAll solves the equations on a pool of workers and returns their solutions in input order
+// Each worker takes the next unsolved equation until none are left. If the context is cancelled the
+// workers stop straight away, abandoning any search in progress, and the context's error is returned
+// along with the solutions finished so far.
+func solveAll(ctx context.Context, eqs []equation, operators []string, order evalOrder, workers int) ([]solution, error) {
+	sols := make([]solution, len(eqs))
+	done := make([]bool, len(eqs)) // Whether each equation was solved before any cancellation
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				sol, err := solve(ctx, eqs[i], operators, order)
+				if err != nil {
+					continue // Drain the remaining jobs without solving them
+				}
+				slog.Debug("solved equation", "line", sol.eq.line, "answer", sol.eq.answer, "assignments", sol.assignments)
+				sols[i], done[i] = sol, true // Each index is written by one worker only
+			}
+		}()
+	}
+
+	// Hand out the equations in order until they run out or the context is cancelled
+feed:
+	for i := range eqs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		var solved int
+		for _, d := range done {
+			if d {
+				solved++
+			}
+		}
+		return sols, fmt.Errorf("stopped after solving %d of %d equations: %w", solved, len(eqs), err)
+	}
+	return sols, nil
+}
+
+// sumSolvable adds up the answers of the solvable equations
+// The sum is taken in input order once every worker is finished, so it doesn't depend on scheduling
+func sumSolvable(sols []solution) int64 {
+	var sum int64
+	for _, s := range sols {
+		if s.solvable() {
+			sum += s.eq.answer
+		}
+	}
+	return sum
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Formats of the per-equation report
+const (
+	reportText string = "text"
+	reportJSON string = "json"
+)
+
+// solutionRecord is a solution as written to a JSON report, one record per line
+type solutionRecord struct {
+	Line        int     `json:"line"`
+	Answer      int64   `json:"answer"`
+	Values      []int64 `json:"values"`
+	Order       string  `json:"order"`
+	Solvable    bool    `json:"solvable"`
+	Expression  string  `json:"expression,omitempty"` // First satisfying expression
+	Assignments int     `json:"assignments"`          // Number of satisfying operator assignments
+}
+
+// writeReport writes every solution, in input order, in the given format
+// The text report ends with the number of solvable equations and the sum of their answers
+func writeReport(w io.Writer, sols []solution, format string) error {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case reportText:
+		var solvable int
+		var sum int64
+		for _, s := range sols {
+			if !s.solvable() {
+				fmt.Fprintf(bw, "line %d: unsolvable: %s\n", s.eq.line, s.expression())
+				continue
+			}
+			solvable++
+			sum += s.eq.answer
+			fmt.Fprintf(bw, "line %d: solvable (%d %s): %s\n", s.eq.line, s.assignments, plural(s.assignments, "assignment"), s.expression())
+		}
+		fmt.Fprintf(bw, "solvable: %d/%d, sum: %d\n", solvable, len(sols), sum)
+	case reportJSON:
+		enc := json.NewEncoder(bw)
+		for _, s := range sols {
+			rec := solutionRecord{
+				Line:        s.eq.line,
+				Answer:      s.eq.answer,
+				Values:      s.eq.vals,
+				Order:       s.order.String(),
+				Solvable:    s.solvable(),
+				Assignments: s.assignments,
+			}
+			if rec.Solvable {
+				rec.Expression = s.expression()
+			}
+			if err := enc.Encode(rec); err != nil {
+				return fmt.Errorf("error writing report: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown report format %q, expected %s or %s", format, reportText, reportJSON)
+	}
+	return bw.Flush()
+}
+
+// plural adds an s to word unless n is 1
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
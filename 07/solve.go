@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// solution is the outcome of trying every operator assignment on an equation
+type solution struct {
+	eq          equation // Equation that was solved
+	order       evalOrder
+	operators   []string // First satisfying assignment, in the order ops are tried, nil if unsolvable
+	assignments int      // Number of satisfying assignments
+}
+
+// Number of search steps between checks for cancellation
+const cancelCheckEvery = 1 << 12
+
+// canceller notices a cancelled context without checking it on every step of a search
+type canceller struct {
+	ctx   context.Context
+	steps int
+	err   error // Set once the context is found to be done
+}
+
+// stopped checks, every so many calls, whether the context is done
+func (c *canceller) stopped() bool {
+	c.steps++
+	if c.err == nil && c.steps%cancelCheckEvery == 0 {
+		c.err = c.ctx.Err()
+	}
+	return c.err != nil
+}
+
+// solve tries every assignment of operators to the gaps between the values, evaluated in the given order
+// Returns the context's error if it is cancelled before the search finishes
+func solve(ctx context.Context, eq equation, operators []string, order evalOrder) (solution, error) {
+	stop := &canceller{ctx: ctx}
+	var sol solution
+	if order != orderLeftToRight {
+		sol = solveTrees(eq, operators, order, stop)
+	} else {
+		sol = solveLeftToRight(eq, operators, stop)
+	}
+	return sol, stop.err
+}
+
+// solveLeftToRight solves an equation under the puzzle's rules
+// Assignments are explored depth first, so a running value that has already passed the answer is
+// abandoned as long as the values left can't shrink it. A running value that overflows is abandoned
+// too, the same as an expression tree that can't be evaluated.
+func solveLeftToRight(eq equation, operators []string, stop *canceller) solution {
+	sol := solution{eq: eq, order: orderLeftToRight}
+	if len(eq.vals) == 0 {
+		return sol
+	}
+
+	// canPrune[i] is true when every value from i on is at least 1, so no operator can make the result smaller
+	canPrune := make([]bool, len(eq.vals)+1)
+	canPrune[len(eq.vals)] = true
+	for i := len(eq.vals) - 1; i >= 0; i-- {
+		canPrune[i] = canPrune[i+1] && eq.vals[i] >= 1
+	}
+
+	chosen := make([]string, len(eq.vals)-1)
+	var search func(i int, acc int64)
+	search = func(i int, acc int64) {
+		if stop.stopped() {
+			return
+		}
+		if i == len(eq.vals) {
+			if acc == eq.answer {
+				if sol.assignments == 0 {
+					sol.operators = append([]string{}, chosen...)
+				}
+				sol.assignments++
+			}
+			return
+		}
+		if acc > eq.answer && canPrune[i] {
+			return
+		}
+		for _, op := range operators {
+			next, ok := doChecked(op, acc, eq.vals[i])
+			if !ok {
+				continue
+			}
+			chosen[i-1] = op
+			search(i+1, next)
+		}
+	}
+	search(1, eq.vals[0])
+	return sol
+}
+
+// solveTrees solves an equation by building and evaluating the expression tree of each assignment
+// Extending an expression only ever replaces its rightmost subtree with a bigger one, whatever the
+// grouping, so the tree of the values so far is a lower bound on the result. Assignments whose
+// partial tree has already passed the answer are abandoned as long as the values left can't shrink it.
+func solveTrees(eq equation, operators []string, order evalOrder, stop *canceller) solution {
+	sol := solution{eq: eq, order: order}
+	if len(eq.vals) == 0 {
+		return sol
+	}
+
+	canPrune := make([]bool, len(eq.vals)+1)
+	canPrune[len(eq.vals)] = true
+	for i := len(eq.vals) - 1; i >= 0; i-- {
+		canPrune[i] = canPrune[i+1] && eq.vals[i] >= 1
+	}
+
+	var tb treeBuilder
+	chosen := make([]string, len(eq.vals)-1)
+	var search func(i int)
+	search = func(i int) {
+		if stop.stopped() {
+			return
+		}
+		v, ok := tb.build(eq.vals[:i+1], chosen[:i], order).eval()
+		if i == len(chosen) {
+			if ok && v == eq.answer {
+				if sol.assignments == 0 {
+					sol.operators = append([]string{}, chosen...)
+				}
+				sol.assignments++
+			}
+			return
+		}
+		if (!ok || v > eq.answer) && canPrune[i+1] {
+			return
+		}
+		for _, op := range operators {
+			chosen[i] = op
+			search(i + 1)
+		}
+	}
+	search(0)
+	return sol
+}
+
+// solvable checks if at least one operator assignment gives the answer
+func (s solution) solvable() bool {
+	return s.assignments > 0
+}
+
+// expression renders the satisfying assignment, e.g. 81 + 40 * 27 = 3267
+// Orders other than left to right show their grouping, e.g. 81 + (40 * 27) = 3267
+// Unsolvable equations are rendered as they appear in the input
+func (s solution) expression() string {
+	var sb strings.Builder
+	if !s.solvable() {
+		sb.WriteString(strconv.FormatInt(s.eq.answer, 10))
+		sb.WriteString(delimColon)
+		for _, v := range s.eq.vals {
+			sb.WriteString(delimSpace)
+			sb.WriteString(strconv.FormatInt(v, 10))
+		}
+		return sb.String()
+	}
+
+	if s.order != orderLeftToRight {
+		return buildTree(s.eq.vals, s.operators, s.order).String() + " = " + strconv.FormatInt(s.eq.answer, 10)
+	}
+	for i, v := range s.eq.vals {
+		if i > 0 {
+			sb.WriteString(" " + s.operators[i-1] + " ")
+		}
+		sb.WriteString(strconv.FormatInt(v, 10))
+	}
+	sb.WriteString(" = " + strconv.FormatInt(s.eq.answer, 10))
+	return sb.String()
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"regexp"
+	"strings"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/logging"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 8: Challenge
@@ -31,91 +37,83 @@ type change struct {
 }
 
 func main() {
+	run := result.Main(8, filename)
+
 	// 0. Read the grid from the input file
 	m, err := readInput(filename)
 	if err != nil {
-		os.Exit(1) // Exit if the file cannot be read
+		run.Fatal(err)
 	}
 
 	// 1. Flatten the 2D grid into a single slice
 	mf := flatten2dSlice(m)
 
-	fmt.Println("Initial Map\n")
-	fmt.Println(mapToString(m))
-	fmt.Println("\n----------------------------\n")
+	logMap("initial map", m)
 
 	// 2. Calculate antenna pairs based on frequency and position
 	pairs := calcAntennaPairs(mf, mf)
 
-	// 3. Calculate and display antinodes without resonance harmonics
-	an := getAllAntinodes(pairs, m, false)
-	mapWithAntinodes := updateMapWithAntinodes(m, an)
-
-	fmt.Println("Antinodes Map\n")
-	fmt.Println(mapToString(mapWithAntinodes))
-	fmt.Println("Antinodes Count: ", len(an))
-	fmt.Println("\n----------------------------\n")
-
-	// 4. Calculate and display antinodes with resonance harmonics
-	anrh := getAllAntinodes(pairs, m, true)
-	mapWithAntinodesrh := updateMapWithAntinodes(m, anrh)
+	// 3. Calculate antinodes without resonance harmonics
+	run.Part(1, func() (any, error) {
+		an := getAllAntinodes(pairs, m, false)
+		logMap("antinodes map", updateMapWithAntinodes(m, an))
+		return len(an), nil
+	})
+
+	// 4. Calculate antinodes with resonance harmonics
+	run.Part(2, func() (any, error) {
+		anrh := getAllAntinodes(pairs, m, true)
+		logMap("antinodes map with resonance harmonics", updateMapWithAntinodes(m, anrh))
+		return len(anrh), nil
+	})
+
+	run.Finish()
+}
 
-	fmt.Println("Antinodes Map with Resonance Harmonics\n")
-	fmt.Println(mapToString(mapWithAntinodesrh))
-	fmt.Println("Antinodes Count with Resonance Harmonics: ", len(anrh))
+// Logs a map at debug level, one record per row so it stays readable in the text format
+func logMap(title string, m [][]cell) {
+	if !logging.Enabled(slog.LevelDebug) {
+		return
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(mapToString(m), "\n"), "\n") {
+		slog.Debug(title, "line", i, "text", line)
+	}
 }
 
 // Reads the input file and converts it into a 2D grid of cells
 func readInput(fname string) ([][]cell, error) {
-	cells := make([][]cell, 0) // 2D grid
-
 	file, err := os.Open(fname)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file:  %w", err)
+		return nil, fmt.Errorf("error opening file [%s]: %w", fname, err)
 	}
 	defer file.Close()
 
-	reader := bufio.NewReader(file)
-	var row []cell
-	var i, j int
+	cells, err := parseGrid(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
+	}
+	return cells, nil
+}
 
+// Parses a rectangular grid of runes into a 2D slice of cells
+func parseGrid(r io.Reader) ([][]cell, error) {
 	// Compile the regex for antenna characters
-	r, err := regexp.Compile(regex)
+	antenna, err := regexp.Compile(regex)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex string: %s", err)
 	}
 
-	for {
-		// Read one character at a time
-		char, _, err := reader.ReadRune()
-		if err != nil {
-			if err.Error() == "EOF" { // End of file
-				if len(row) > 0 {
-					cells = append(cells, row) // Append the last row
-				}
-				break
-			}
-			return nil, fmt.Errorf("error reading file: %w", err)
-		}
-
-		if char == '\n' { // Newline indicates the end of a row
-			cells = append(cells, row)
-			row = make([]cell, 0)
-			j++   // Increment y-coordinate
-			i = 0 // Reset x-coordinate
-			continue
-		}
-
-		// Create a cell for the current character
-		currCell := cell{x: i, y: j, frequency: char}
-
-		// Mark as an antenna if it matches the regex
-		if r.MatchString(string(char)) {
-			currCell.isAntenna = true
+	grid, err := input.Grid(r)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([][]cell, len(grid))
+	for j, row := range grid {
+		cells[j] = make([]cell, len(row))
+		for i, char := range row {
+			// Mark as an antenna if it matches the regex
+			cells[j][i] = cell{x: i, y: j, frequency: char, isAntenna: antenna.MatchString(string(char))}
 		}
-
-		row = append(row, currCell)
-		i++
 	}
 	return cells, nil
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func FuzzParseGrid(f *testing.F) {
+	f.Add("............\n........0...\n.....0......\n.......0....\n....0.......\n......A.....\n............\n............\n........A...\n.........A..\n............\n............\n")
+	f.Add("..a\n.a\n")
+	f.Add("\n")
+	f.Add("a.\n\n.a\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		m, err := parseGrid(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		for y, row := range m {
+			if len(row) != len(m[0]) {
+				t.Fatalf("row %d has %d cells, row 0 has %d", y, len(row), len(m[0]))
+			}
+		}
+		mf := flatten2dSlice(m)
+		pairs := calcAntennaPairs(mf, mf)
+		for _, harmonics := range []bool{false, true} {
+			for pos := range getAllAntinodes(pairs, m, harmonics) {
+				if !inBounds(pos[0], pos[1], m) {
+					t.Fatalf("antinode %v off the map", pos)
+				}
+			}
+		}
+	})
+}
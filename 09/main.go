@@ -2,11 +2,16 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"runtime/pprof"
-	"strconv"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 9: Challenge
@@ -19,112 +24,118 @@ const (
 
 // Entry point for the program
 func main() {
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile to this file")
+	run := result.Main(9, filename)
+
 	// Read and parse the input file into a slice of blocks
 	blocks, err := readInput(filename)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		run.Fatal(err)
 	}
 
-	f, err := os.Create("profile.pf")
-	if err != nil {
-		fmt.Println(err)
-	}
-	defer f.Close()
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			slog.Error("creating CPU profile", "file", *cpuProfile, "err", err)
+			os.Exit(1)
+		}
+		defer f.Close()
 
-	if err := pprof.StartCPUProfile(f); err != nil {
-		log.Fatal("could not start CPU profile: ", err)
+		if err := pprof.StartCPUProfile(f); err != nil {
+			slog.Error("starting CPU profile", "err", err)
+			os.Exit(1)
+		}
+		defer pprof.StopCPUProfile()
 	}
-	defer pprof.StopCPUProfile()
 
 	// Part 1: Move blocks and calculate checksum
-	moved := true
-	finishedBlocks := append([]int{}, blocks...) // Copy of blocks for manipulation
-	for moved {
-		// Move blocks until no movement occurs
-		finishedBlocks, moved = move(finishedBlocks)
-		// Create a new copy to avoid mutating the original
-		finishedBlocks = append([]int{}, finishedBlocks...)
-	}
-	fmt.Println("part 1 checksum:", calcChecksum(finishedBlocks)) // Calculate and print checksum for Part 1
-
-	// Part 2: Rearrange blocks and calculate checksum
-	idToSize := getIDToSize(blocks)              // Map block IDs to their sizes
-	fileEndIndices := getLastFileIndices(blocks) // Get indices of the last files
-	fsis := getFreeSpaceIndices(blocks)          // Get indices of free space
-
-	// Move entire blocks based on available free space
-	for _, fei := range fileEndIndices {
-		blocks = moveWholeBlock(blocks, fsis, fei, idToSize) // Move the block
-	}
-
-	// Calculate and print checksum for Part 2
-	fmt.Println("part 2 checksum:", calcChecksum(blocks))
+	run.Part(1, func() (any, error) {
+		moved := true
+		finishedBlocks := append([]int{}, blocks...) // Copy of blocks for manipulation
+		for moved {
+			// Move blocks until no movement occurs
+			finishedBlocks, moved = move(finishedBlocks)
+			// Create a new copy to avoid mutating the original
+			finishedBlocks = append([]int{}, finishedBlocks...)
+		}
+		return calcChecksum(finishedBlocks), nil
+	})
+
+	// Part 2: Move whole files and calculate checksum
+	run.Part(2, func() (any, error) {
+		return calcChecksum(compactFiles(blocks)), nil
+	})
+
+	run.Finish()
 }
 
 // Reads the input file and parses it into a slice of blocks
 func readInput(fname string) ([]int, error) {
-	ids := make([]int, 0) // Slice to store block IDs
-
 	// Open the input file
 	file, err := os.Open(fname)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file:  %w", err)
+		return nil, fmt.Errorf("error opening file [%s]: %w", fname, err)
 	}
 	defer file.Close()
 
-	reader := bufio.NewReader(file) // Reader for efficient file reading
-	var fileIDIdx int               // Counter for file IDs
+	ids, err := parseDiskMap(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
+	}
+	return ids, nil
+}
+
+// Parses a disk map (e.g., "2333133121414131402") into a slice of blocks
+// The map is the first line, anything after it must be blank
+func parseDiskMap(r io.Reader) ([]int, error) {
+	ids := make([]int, 0)                         // Slice to store block IDs
+	reader := bufio.NewReader(input.Normalize(r)) // Reader for efficient reading
+	var fileIDIdx int                             // Counter for file IDs
+	line, column := 1, 0                          // Position of the last byte read
 
 	for {
-		// Read one character (file length) at a time
-		lenFileRu, _, err := reader.ReadRune()
+		b, err := reader.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
 		if err != nil {
-			if err.Error() == "EOF" { // End of file
-				break
-			}
-			return nil, fmt.Errorf("error reading file: %w", err)
+			return nil, &input.ParseError{Line: line, Err: err}
 		}
-		if lenFileRu == '\n' { // End of a line
-			return ids, nil
+		if b == '\n' { // End of the map
+			line++
+			break
 		}
-
-		// Parse file length
-		var lenFile, lenFree int
-		lenFile, err = strconv.Atoi(string(lenFileRu))
-		if err != nil {
-			return nil, fmt.Errorf("error converting file length input to int: %w", err)
+		column++
+		if b < '0' || b > '9' {
+			return nil, &input.ParseError{Line: line, Column: column, Err: fmt.Errorf("expected a digit, found %q", b)}
 		}
 
-		// Add blocks for the file
-		for i := 0; i < lenFile; i++ {
-			ids = append(ids, fileIDIdx)
+		// Even positions are file lengths, odd ones free space lengths
+		id := freeSpaceVal
+		if column%2 == 1 {
+			id = fileIDIdx
+			fileIDIdx++
 		}
-		fileIDIdx++
-
-		// Parse free space length
-		lenFreeRu, _, err := reader.ReadRune()
-		if err != nil {
-			if err.Error() == "EOF" {
-				break
-			}
-			return nil, fmt.Errorf("error reading file: %w", err)
+		for i := 0; i < int(b-'0'); i++ {
+			ids = append(ids, id)
 		}
-		if lenFreeRu == '\n' { // End of a line
+	}
+
+	// Only blank lines may follow the map
+	for {
+		b, err := reader.ReadByte()
+		if errors.Is(err, io.EOF) {
 			return ids, nil
 		}
-
-		lenFree, err = strconv.Atoi(string(lenFreeRu))
 		if err != nil {
-			return nil, fmt.Errorf("error converting free length input to int: %w", err)
+			return nil, &input.ParseError{Line: line, Err: err}
 		}
-
-		// Add blocks for the free space
-		for i := 0; i < lenFree; i++ {
-			ids = append(ids, freeSpaceVal)
+		if b == '\n' {
+			line++
+			continue
 		}
+		return nil, &input.ParseError{Line: line, Column: 1, Err: errors.New("unexpected content after the disk map")}
 	}
-	return ids, nil
 }
 
 // Converts blocks to a string representation for debugging
@@ -163,47 +174,48 @@ func move(blocks []int) ([]int, bool) {
 	return swap(blocksCopy, freeStartIndex, fileIndex), true
 }
 
-// Creates a map of block IDs to their sizes
-func getIDToSize(blocks []int) map[int]int {
-	idToSizeMap := make(map[int]int)
-
-	for _, id := range blocks {
-		idToSizeMap[id]++ // Increment size for each block ID
-	}
-	return idToSizeMap
-}
-
-// Calculates the length of consecutive free blocks starting at a given index
-func getFreeBlockLengths(blocks []int) []int {
-	freeLengthCounts := make([]int, 0)
-	var freeLengthCount int
+// Moves whole files, highest ID first, to the leftmost span of free space to their left that fits them
+// Each file is moved at most once, a file with no span that fits stays where it is
+func compactFiles(blocks []int) []int {
+	blocks = append([]int{}, blocks...) // Copy of blocks for manipulation
+	firstFree := 0                      // No free space comes before this index
+	nextID := len(blocks)               // Files with this ID or above have been moved already
 
-	for _, block := range blocks {
-		if block != freeSpaceVal {
-			freeLengthCount = 0
+	for end := len(blocks) - 1; end >= 0; end-- {
+		id := blocks[end]
+		if id == freeSpaceVal || id >= nextID {
 			continue
 		}
-		freeLengthCount++
-		freeLengthCounts = append(freeLengthCounts, freeLengthCount)
-	}
-	return freeLengthCounts
-}
-
-// Moves an entire block to available free space
-func moveWholeBlock(blocks []int, freeStartIndices []int, lastFileIndex int, idToSize map[int]int) []int {
-	blocksCopy := append([]int{}, blocks...) // Create a copy of the blocks
+		nextID = id
 
-	freeLengthCounts := getFreeBlockLengths(blocksCopy)
-	for i, freeStartIndex := range freeStartIndices {
-		// Get free space length
-		lastFileSize := idToSize[blocks[lastFileIndex]] // Get size of the last file block
+		// Find where the file starts
+		start := end
+		for start > 0 && blocks[start-1] == id {
+			start--
+		}
+		size := end - start + 1
 
-		// Move block if there is enough free space and it's valid to move
-		if freeLengthCounts[i] >= lastFileSize && lastFileIndex > freeStartIndex {
-			blocksCopy = moveBlock(blocks, lastFileSize, lastFileIndex, freeStartIndex)
+		// Find the leftmost span of free space before the file that is big enough
+		for firstFree < len(blocks) && blocks[firstFree] != freeSpaceVal {
+			firstFree++
+		}
+		free := 0 // Length of the span of free space ending at i
+		for i := firstFree; i < start; i++ {
+			if blocks[i] != freeSpaceVal {
+				free = 0
+				continue
+			}
+			free++
+			if free == size {
+				for k := 0; k < size; k++ {
+					blocks[i-size+1+k], blocks[start+k] = id, freeSpaceVal
+				}
+				break
+			}
 		}
+		end = start
 	}
-	return blocksCopy
+	return blocks
 }
 
 // Returns the index of the first free space
@@ -216,38 +228,6 @@ func getFirstFreeSpaceIndex(blocks []int) int {
 	return -1 // No free space found
 }
 
-// Moves a block from one position to another
-func moveBlock(blocks []int, lastFileSize, lastFileIndex, freeStartIndex int) []int {
-	for i := 0; i < lastFileSize; i++ {
-		blocks = swap(blocks, freeStartIndex+i, lastFileIndex-i)
-	}
-	return blocks
-}
-
-// Returns a slice of indices of all free spaces
-func getFreeSpaceIndices(blocks []int) []int {
-	freeSpaceIndices := make([]int, 0)
-	for i := range blocks {
-		if blocks[i] == freeSpaceVal {
-			freeSpaceIndices = append(freeSpaceIndices, i)
-		}
-	}
-	return freeSpaceIndices
-}
-
-// Returns a slice of indices of the last files in the blocks
-func getLastFileIndices(blocks []int) []int {
-	lastFileIndices := make([]int, 0)
-	var lastVal int
-	for i := len(blocks) - 1; i >= 0; i-- {
-		if blocks[i] != freeSpaceVal && blocks[i] != lastVal {
-			lastFileIndices = append(lastFileIndices, i)
-			lastVal = blocks[i]
-		}
-	}
-	return lastFileIndices
-}
-
 // Returns the index of the last file in the blocks
 func getLastFileIndex(blocks []int) int {
 	for i := len(blocks) - 1; i >= 0; i-- {
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func TestCompactFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{name: "example", input: "2333133121414131402\n", want: 2858},
+		{name: "no file fits", input: "12345\n", want: 132},
+		// The last file fills the gap, so the one before it finds no room
+		{name: "moved file takes the space", input: "12112\n", want: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := parseDiskMap(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := calcChecksum(compactFiles(blocks)); got != tt.want {
+				t.Errorf("got checksum %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseDiskMap(f *testing.F) {
+	f.Add("2333133121414131402\n")
+	f.Add("12345")
+	f.Add("12345\r\n")
+	f.Add("12345\n\n  \n")
+	f.Add("12345\n6\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		blocks, err := parseDiskMap(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			if pe.Line < 1 {
+				t.Fatalf("error without a line: %v", err)
+			}
+			return
+		}
+		last := 0 // Files are laid out in ID order, zero length files leave gaps in the IDs
+		for _, b := range blocks {
+			if b == freeSpaceVal {
+				continue
+			}
+			if b < last {
+				t.Fatalf("block with ID %d after ID %d", b, last)
+			}
+			last = b
+		}
+	})
+}
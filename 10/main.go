@@ -1,9 +1,14 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/logging"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
 )
 
 // Advent of Code 2024 - Day 10: Challenge
@@ -36,54 +41,51 @@ type cell struct {
 }
 
 func main() {
-	tmap := readInput(fileName)
-	puzzleToString(tmap)
+	run := result.Main(10, fileName)
+
+	tmap, err := readInput(fileName)
+	if err != nil {
+		run.Fatal(err)
+	}
+	logPuzzle(tmap)
+
+	// Part 1: Count the paths climbing from a trailhead to a peak
+	run.Part(1, func() (any, error) {
+		return len(findSolutions(tmap, solution, allDirections)), nil
+	})
 
-	solutions1 := findSolutions(tmap, solution, allDirections)
-	fmt.Println(len(solutions1)) // Print the number of solutions found
+	run.Finish()
 }
 
 // readInput reads the grid from the file and converts it to a 2D slice of cells
-func readInput(fname string) [][]cell {
-	cells := make([][]cell, 0)
-
+func readInput(fname string) ([][]cell, error) {
 	file, err := os.Open(fname)
 	if err != nil {
-		fmt.Println("Error opening file:", err)
-		return nil
+		return nil, fmt.Errorf("error opening file [%s]: %w", fname, err)
 	}
 	defer file.Close()
 
-	reader := bufio.NewReader(file)
-	var row []cell
-	var i, j int
-
-	for {
-		char, _, err := reader.ReadRune()
-		if err != nil {
-			if err.Error() == "EOF" {
-				if len(row) > 0 {
-					cells = append(cells, row) // Append the last row
-				}
-				break
-			}
-			fmt.Println("Error reading file:", err)
-			return nil
-		}
+	cells, err := parseGrid(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file [%s]: %w", fname, err)
+	}
+	return cells, nil
+}
 
-		if char == '\n' { // Handle newlines as row separators
-			cells = append(cells, row)
-			row = make([]cell, 0)
-			j++ // Move to the next row
-			i = 0
-			continue
+// Parses a rectangular grid of runes into a 2D slice of cells
+func parseGrid(r io.Reader) ([][]cell, error) {
+	grid, err := input.Grid(r)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([][]cell, len(grid))
+	for j, row := range grid {
+		cells[j] = make([]cell, len(row))
+		for i, char := range row {
+			cells[j][i] = cell{x: i, y: j, val: char}
 		}
-
-		// Add the character as a cell to the current row
-		row = append(row, cell{x: i, y: j, val: char})
-		i++
 	}
-	return cells
+	return cells, nil
 }
 
 // findSolutions finds all paths in the grid that match the target word
@@ -94,9 +96,9 @@ func findSolutions(tmap [][]cell, solution string, validDirections []direction)
 	for i, row := range tmap {
 		for j := range row {
 			// If the cell matches the first character, start exploring paths
-			fmt.Printf("exploring %s==%s\n", string(tmap[i][j].val), string(solution[0]))
+			logging.Trace("exploring", "x", i, "y", j, "val", string(tmap[i][j].val), "want", string(solution[0]))
 			if tmap[i][j].val == rune(solution[0]) {
-				fmt.Println("\tfound")
+				logging.Trace("found start", "x", i, "y", j)
 				path := []cell{tmap[i][j]} // Start a new path
 				for _, dir := range validDirections {
 					// Explore all paths in the specified directions
@@ -125,7 +127,7 @@ func dfs(tmap [][]cell, x, y int, remainingSolution string, path []cell, dir dir
 	nx, ny := x+dir.dx, y+dir.dy
 
 	// Check bounds and character match for the next cell
-	fmt.Println("remainingSolution: %s", string(remainingSolution[0]))
+	logging.Trace("checking next cell", "x", nx, "y", ny, "want", string(remainingSolution[0]))
 	if inBounds(nx, ny, tmap) && tmap[nx][ny].val == rune(remainingSolution[0]) && !visited(path, tmap[nx][ny]) {
 		newPath := append([]cell{}, path...)    // Create a new path
 		newPath = append(newPath, tmap[nx][ny]) // Add the next cell
@@ -145,12 +147,16 @@ func visited(path []cell, c cell) bool {
 	return false
 }
 
-// puzzleToString prints the grid to the console
-func puzzleToString(s [][]cell) {
-	for _, row := range s {
-		for _, cell := range row {
-			fmt.Print(string(cell.val))
+// logPuzzle logs the grid one row at a time at debug level
+func logPuzzle(s [][]cell) {
+	if !logging.Enabled(slog.LevelDebug) {
+		return
+	}
+	for y, row := range s {
+		vals := make([]rune, len(row))
+		for x, cell := range row {
+			vals[x] = cell.val
 		}
-		fmt.Println()
+		slog.Debug("puzzle", "row", y, "cells", string(vals))
 	}
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func FuzzParseGrid(f *testing.F) {
+	f.Add("89010123\n78121874\n87430965\n96549874\n45678903\n32019012\n01329801\n10456732\n")
+	f.Add("0123\n1234\n876\n")
+	f.Add("\n")
+	f.Add("01\n\n23\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		tmap, err := parseGrid(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		for y, row := range tmap {
+			if len(row) != len(tmap[0]) {
+				t.Fatalf("row %d has %d cells, row 0 has %d", y, len(row), len(tmap[0]))
+			}
+		}
+		for _, path := range findSolutions(tmap, solution, allDirections) {
+			if len(path) != len(solution) {
+				t.Fatalf("path of %d cells for a %d step trail", len(path), len(solution))
+			}
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/digits"
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
+)
+
+// Advent of Code 2024 - Day 11: Plutonian Pebbles
+// https://adventofcode.com/2024/day/11
+
+const (
+	fileName    string = "input.txt" // Name of the input file
+	part1Blinks int    = 25          // Blinks counted for part 1
+	part2Blinks int    = 75          // Blinks counted for part 2
+	multiplier  int64  = 2024        // Stones with no other rule are multiplied by this
+)
+
+// Solver holds the parsed puzzle input and solves both parts
+type Solver struct {
+	stones []int64         // Numbers engraved on the stones, in order
+	memo   map[memoKey]int // Number of stones a single stone becomes, see count
+}
+
+// memoKey is a stone and the number of blinks still to come
+type memoKey struct {
+	value  int64
+	blinks int
+}
+
+func main() {
+	distinct := flag.Bool("distinct", false, "print the number of distinct stone values after each blink to stderr")
+	run := result.Main(11, fileName)
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		run.Fatal(fmt.Errorf("error opening file [%s]: %w", fileName, err))
+	}
+	defer f.Close()
+
+	s, err := readInput(f)
+	if err != nil {
+		run.Fatal(fmt.Errorf("error reading file [%s]: %w", fileName, err))
+	}
+
+	run.Part(1, func() (any, error) {
+		return s.part1()
+	})
+	run.Part(2, func() (any, error) {
+		return s.part2()
+	})
+	run.Finish()
+
+	// The breakdown goes to stderr so stdout holds only the results, whatever the output format
+	if *distinct {
+		perBlink, err := s.distinctPerBlink(part2Blinks)
+		if err != nil {
+			slog.Error("counting distinct values", "err", err)
+			os.Exit(1)
+		}
+		for i, n := range perBlink {
+			fmt.Fprintf(os.Stderr, "\t blink %d: %d distinct values\n", i+1, n)
+		}
+	}
+}
+
+// Reads the puzzle input, the numbers on the stones separated by whitespace
+func readInput(r io.Reader) (*Solver, error) {
+	s := &Solver{memo: make(map[memoKey]int)}
+
+	scanner := bufio.NewScanner(input.Normalize(r))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		for _, field := range input.Fields(line) {
+			v, err := strconv.ParseInt(field.Text, 10, 64)
+			if err != nil || v < 0 {
+				return nil, &input.ParseError{Line: lineNum, Column: field.Column, Text: line, Err: fmt.Errorf("expected non-negative int, found %q", field.Text)}
+			}
+			s.stones = append(s.stones, v)
+		}
+	}
+
+	// Check for any errors encountered during scanning
+	if err := scanner.Err(); err != nil {
+		return nil, &input.ParseError{Line: lineNum + 1, Err: fmt.Errorf("error reading input: %w", err)}
+	}
+	return s, nil
+}
+
+// Counts the stones after 25 blinks
+func (s *Solver) part1() (int, error) {
+	return s.countAll(part1Blinks)
+}
+
+// Counts the stones after 75 blinks
+func (s *Solver) part2() (int, error) {
+	return s.countAll(part2Blinks)
+}
+
+// Counts the stones in the line after the given number of blinks
+// Stones never affect their neighbours, so each one is counted on its own
+func (s *Solver) countAll(blinks int) (int, error) {
+	var total int
+	for _, v := range s.stones {
+		n, err := s.count(v, blinks)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Counts the stones a single stone becomes after the given number of blinks
+// The same values turn up over and over, so counts are memoized by value and blinks left
+func (s *Solver) count(value int64, blinks int) (int, error) {
+	if blinks == 0 {
+		return 1, nil
+	}
+	key := memoKey{value: value, blinks: blinks}
+	if n, ok := s.memo[key]; ok {
+		return n, nil
+	}
+
+	stones, err := blink(value)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, next := range stones {
+		c, err := s.count(next, blinks-1)
+		if err != nil {
+			return 0, err
+		}
+		n += c
+	}
+	s.memo[key] = n
+	return n, nil
+}
+
+// Returns the stones a stone becomes after one blink
+// Returns an error if the stone's number no longer fits in an int64
+func blink(value int64) ([]int64, error) {
+	// A 0 becomes a 1
+	if value == 0 {
+		return []int64{1}, nil
+	}
+	// An even number of digits splits into two stones, the left and right halves
+	if left, right, ok := digits.Split(value); ok {
+		return []int64{left, right}, nil
+	}
+	// Anything else is multiplied by 2024
+	next, ok := digits.Mul(value, multiplier)
+	if !ok {
+		return nil, fmt.Errorf("stone %d multiplied by %d overflows an int64", value, multiplier)
+	}
+	return []int64{next}, nil
+}
+
+// Returns the number of distinct stone values after each of the given number of blinks
+// Only how many stones carry each value matters, so the line is kept as counts per value
+func (s *Solver) distinctPerBlink(blinks int) ([]int, error) {
+	counts := make(map[int64]int)
+	for _, v := range s.stones {
+		counts[v]++
+	}
+
+	distinct := make([]int, 0, blinks)
+	for i := 0; i < blinks; i++ {
+		next := make(map[int64]int, len(counts))
+		for v, n := range counts {
+			stones, err := blink(v)
+			if err != nil {
+				return nil, err
+			}
+			for _, nv := range stones {
+				next[nv] += n
+			}
+		}
+		counts = next
+		distinct = append(distinct, len(counts))
+	}
+	return distinct, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		part1 int
+		part2 int
+	}{
+		{name: "example", input: "125 17\n", part1: 55312, part2: 65601038650482},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := readInput(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := s.part1(); err != nil || got != tt.part1 {
+				t.Errorf("part 1: got %d, %v, want %d", got, err, tt.part1)
+			}
+			if got, err := s.part2(); err != nil || got != tt.part2 {
+				t.Errorf("part 2: got %d, %v, want %d", got, err, tt.part2)
+			}
+		})
+	}
+}
+
+func TestBlink(t *testing.T) {
+	// The first example from the puzzle, after a single blink
+	var got []int64
+	for _, v := range []int64{0, 1, 10, 99, 999} {
+		stones, err := blink(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, stones...)
+	}
+	want := []int64{1, 2024, 1, 0, 9, 9, 2021976}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestBlinkOverflow(t *testing.T) {
+	// 19 digits is odd, so the stone is multiplied by 2024 on the next blink and no longer fits
+	s, err := readInput(strings.NewReader("999999999999999\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.countAll(1); err != nil {
+		t.Fatalf("first blink: %v", err)
+	}
+	if n, err := s.countAll(2); err == nil {
+		t.Errorf("got %d stones after the second blink, want an overflow", n)
+	}
+	if d, err := s.distinctPerBlink(2); err == nil {
+		t.Errorf("got %v distinct values, want an overflow", d)
+	}
+}
+
+func TestDistinctPerBlink(t *testing.T) {
+	// Distinct values after each of the blinks of the second example listed in the puzzle
+	s, err := readInput(strings.NewReader("125 17\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.distinctPerBlink(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{3, 4, 5, 8, 12, 15}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func FuzzReadInput(f *testing.F) {
+	f.Add("125 17\n")
+	f.Add("0 1 10 99 999\n")
+	f.Add("1 -2\n")
+	f.Add("1\t2\n\n3 x\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		solver, err := readInput(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		got, err := solver.countAll(5)
+		if err != nil {
+			return // Numbers too large to blink
+		}
+		if got < len(solver.stones) {
+			t.Fatalf("%d stones after 5 blinks from %d", got, len(solver.stones))
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
+)
+
+// Advent of Code 2024 - Day 12: Garden Groups
+// https://adventofcode.com/2024/day/12
+
+const (
+	fileName string = "input.txt" // Name of the input file
+)
+
+// Represents a single position in the grid with coordinates and value
+type cell struct {
+	x, y int
+	val  rune
+}
+
+// Solver holds the parsed puzzle input and solves both parts
+type Solver struct {
+	cells    [][]cell // The grid, indexed cells[y][x]
+	regions  []region // Regions of the same plant, in reading order
+	regionOf [][]int  // Index of the region of each cell, indexed like cells
+}
+
+func main() {
+	render := flag.Bool("render", false, "draw the map with each region in its own colour, followed by the cost of each region")
+	run := result.Main(12, fileName)
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		run.Fatal(fmt.Errorf("error opening file [%s]: %w", fileName, err))
+	}
+	defer f.Close()
+
+	s, err := readInput(f)
+	if err != nil {
+		run.Fatal(fmt.Errorf("error reading file [%s]: %w", fileName, err))
+	}
+
+	run.Part(1, func() (any, error) {
+		return s.part1(), nil
+	})
+	run.Part(2, func() (any, error) {
+		return s.part2(), nil
+	})
+	run.Finish()
+
+	if *render {
+		if err := s.render(os.Stdout); err != nil {
+			slog.Error("rendering map", "err", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// Reads the puzzle input into a grid of cells
+// Every line must have the same number of cells
+func readInput(r io.Reader) (*Solver, error) {
+	s := &Solver{}
+
+	grid, err := input.Grid(r)
+	if err != nil {
+		return nil, err
+	}
+	s.cells = make([][]cell, len(grid))
+	for y, row := range grid {
+		s.cells[y] = make([]cell, len(row))
+		for x, char := range row {
+			s.cells[y][x] = cell{x: x, y: y, val: char}
+		}
+	}
+
+	s.regions, s.regionOf = s.findRegions()
+	return s, nil
+}
+
+// Checks if the given coordinates are within the grid bounds
+func (s *Solver) inBounds(x, y int) bool {
+	return y >= 0 && y < len(s.cells) && x >= 0 && x < len(s.cells[y])
+}
+
+// Prices the fencing by area times perimeter of each region
+func (s *Solver) part1() int {
+	var total int
+	for _, r := range s.regions {
+		total += r.area() * r.perimeter
+	}
+	return total
+}
+
+// Prices the fencing by area times number of sides of each region
+func (s *Solver) part2() int {
+	var total int
+	for _, r := range s.regions {
+		total += r.area() * r.sides
+	}
+	return total
+}
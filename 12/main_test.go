@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		part1 int
+		part2 int
+	}{
+		{name: "small", input: "AAAA\nBBCD\nBBCC\nEEEC\n", part1: 140, part2: 80},
+		{name: "holes", input: "OOOOO\nOXOXO\nOOOOO\nOXOXO\nOOOOO\n", part1: 772, part2: 436},
+		{name: "e shape", input: "EEEEE\nEXXXX\nEEEEE\nEXXXX\nEEEEE\n", part1: 692, part2: 236},
+		{name: "diagonal touch", input: "AAAAAA\nAAABBA\nAAABBA\nABBAAA\nABBAAA\nAAAAAA\n", part1: 1184, part2: 368},
+		{
+			name:  "larger",
+			input: "RRRRIICCFF\nRRRRIICCCF\nVVRRRCCFFF\nVVRCCCJFFF\nVVVVCJJCFE\nVVIVCCJJEE\nVVIIICJJEE\nMIIIIIJJEE\nMIIISIJEEE\nMMMISSJEEE\n",
+			part1: 1930,
+			part2: 1206,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := readInput(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.part1(); got != tt.part1 {
+				t.Errorf("part 1: got %d, want %d", got, tt.part1)
+			}
+			if got := s.part2(); got != tt.part2 {
+				t.Errorf("part 2: got %d, want %d", got, tt.part2)
+			}
+		})
+	}
+}
+
+func FuzzReadInput(f *testing.F) {
+	f.Add("AAAA\nBBCD\nBBCC\nEEEC\n")
+	f.Add("AB\nA\n")
+	f.Add("\n")
+	f.Add("AB\n\nAB\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		solver, err := readInput(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		// Bulk discounts can only lower the price
+		if p1, p2 := solver.part1(), solver.part2(); p2 > p1 {
+			t.Fatalf("part 2 price %d above part 1 price %d", p2, p1)
+		}
+	})
+}
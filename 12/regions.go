@@ -0,0 +1,102 @@
+package main
+
+// Movement deltas to the four orthogonal neighbours, in clockwise order starting up
+var neighbours = [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// region is a group of touching cells growing the same plant
+type region struct {
+	plant     rune
+	cells     []cell
+	perimeter int // Number of cell edges bordering another region or the edge of the map
+	sides     int // Number of straight sections of fence, equal to the number of corners
+}
+
+// area is the number of cells in the region
+func (r region) area() int {
+	return len(r.cells)
+}
+
+// findRegions flood fills the grid into regions
+// Returns the regions in reading order of their first cell, and the index of the region of every cell
+func (s *Solver) findRegions() ([]region, [][]int) {
+	cells := s.cells
+	regionOf := make([][]int, len(cells))
+	for y, row := range cells {
+		regionOf[y] = make([]int, len(row))
+		for x := range row {
+			regionOf[y][x] = -1
+		}
+	}
+
+	regions := make([]region, 0)
+	for y, row := range cells {
+		for x, c := range row {
+			if regionOf[y][x] >= 0 {
+				continue
+			}
+
+			// Fill outwards from the first cell of a new region
+			id := len(regions)
+			r := region{plant: c.val}
+			regionOf[y][x] = id
+			queue := []cell{c}
+			for len(queue) > 0 {
+				curr := queue[0]
+				queue = queue[1:]
+				r.cells = append(r.cells, curr)
+				for _, n := range neighbours {
+					nx, ny := curr.x+n[0], curr.y+n[1]
+					if !s.inBounds(nx, ny) || cells[ny][nx].val != r.plant || regionOf[ny][nx] >= 0 {
+						continue
+					}
+					regionOf[ny][nx] = id
+					queue = append(queue, cells[ny][nx])
+				}
+			}
+			regions = append(regions, r)
+		}
+	}
+
+	for i := range regions {
+		regions[i].perimeter = perimeter(regions[i], regionOf, i)
+		regions[i].sides = corners(regions[i], regionOf, i)
+	}
+	return regions, regionOf
+}
+
+// perimeter counts the edges of the region's cells that don't touch another cell of the region
+func perimeter(r region, regionOf [][]int, id int) int {
+	var count int
+	for _, c := range r.cells {
+		for _, n := range neighbours {
+			if !inRegion(regionOf, c.x+n[0], c.y+n[1], id) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// corners counts the corners of the region, which is the same as its number of sides
+// Each cell is checked at its four corners: a corner is outside when both neighbours along it are
+// outside the region, and inside when both are in the region but the diagonal between them isn't
+func corners(r region, regionOf [][]int, id int) int {
+	var count int
+	for _, c := range r.cells {
+		for i, a := range neighbours {
+			b := neighbours[(i+1)%len(neighbours)] // The next neighbour clockwise
+			inA := inRegion(regionOf, c.x+a[0], c.y+a[1], id)
+			inB := inRegion(regionOf, c.x+b[0], c.y+b[1], id)
+			inDiagonal := inRegion(regionOf, c.x+a[0]+b[0], c.y+a[1]+b[1], id)
+			if (!inA && !inB) || (inA && inB && !inDiagonal) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// inRegion checks if the coordinates are on the map and belong to the region
+func inRegion(regionOf [][]int, x, y, id int) bool {
+	return y >= 0 && y < len(regionOf) && x >= 0 && x < len(regionOf[y]) && regionOf[y][x] == id
+}
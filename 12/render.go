@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// ANSI escape codes for rendering
+const (
+	ansiReset string = "\x1b[0m"
+	ansiText  string = "\x1b[1;30m" // Black text over the region colours
+)
+
+// Region colours as xterm 256 colour indices, bright enough to read black text over
+var regionANSI = []int{196, 46, 33, 226, 201, 51, 208, 141, 118, 75, 220, 213, 85, 203, 147, 190}
+
+// render draws the map with each region in its own colour, then lists the regions and their costs
+// Touching regions get different colours, unless a region touches one of each of the palette's colours
+func (s *Solver) render(w io.Writer) error {
+	colours := s.regionColours()
+	bw := bufio.NewWriter(w)
+
+	for y, row := range s.cells {
+		for x, c := range row {
+			fmt.Fprintf(bw, "\x1b[48;5;%dm%s%c%s", regionANSI[colours[s.regionOf[y][x]]], ansiText, c.val, ansiReset)
+		}
+		bw.WriteString("\n")
+	}
+
+	bw.WriteString("\n")
+	for i, r := range s.regions {
+		first := r.cells[0]
+		fmt.Fprintf(bw, "\x1b[48;5;%dm%s %c %s region %d at (%d,%d): area %d, perimeter %d, sides %d, price %d / %d\n",
+			regionANSI[colours[i]], ansiText, r.plant, ansiReset, i+1, first.x, first.y,
+			r.area(), r.perimeter, r.sides, r.area()*r.perimeter, r.area()*r.sides)
+	}
+	return bw.Flush()
+}
+
+// regionColours picks a colour for each region, greedily avoiding the colours of the regions it touches
+// A map with a region touching every colour falls back to cycling through the palette
+func (s *Solver) regionColours() []int {
+	colours := make([]int, len(s.regions))
+	for i, r := range s.regions {
+		// Colours of the neighbouring regions already coloured, which come earlier in reading order
+		used := make(map[int]bool)
+		for _, c := range r.cells {
+			for _, n := range neighbours {
+				nx, ny := c.x+n[0], c.y+n[1]
+				if s.inBounds(nx, ny) && s.regionOf[ny][nx] < i {
+					used[colours[s.regionOf[ny][nx]]] = true
+				}
+			}
+		}
+
+		colours[i] = i % len(regionANSI)
+		for k := range regionANSI {
+			if !used[k] {
+				colours[i] = k
+				break
+			}
+		}
+	}
+	return colours
+}
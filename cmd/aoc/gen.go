@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"time"
+)
+
+// runGen writes a random input for a day, reporting its seed and the answers it was made with to stderr
+//
+//	aoc gen --day 6 [--size 130] [--seed 1] [--out input.txt]
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	day := fs.Int("day", 0, "day to generate an input for")
+	size := fs.Int("size", 0, "size of the input, in units that depend on the day, 0 for the size of a real input")
+	seed := fs.Uint64("seed", 0, "seed of the random input, 0 picks one from the clock")
+	out := fs.String("out", "", "file to write the input to, defaults to stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: aoc gen --day N [--size S] [--seed X] [--out file]")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nsizes:")
+		for _, d := range slices.Sorted(maps.Keys(generators)) {
+			g := generators[d]
+			fmt.Fprintf(fs.Output(), "  day %02d: %s (default %d)\n", d, g.sizeUsage, g.defaultSize)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	g, ok := generators[*day]
+	if !ok {
+		return fmt.Errorf("day %d has no input generator", *day)
+	}
+	if *size < 0 {
+		return fmt.Errorf("size must not be negative, got %d", *size)
+	}
+	if *size == 0 {
+		*size = g.defaultSize
+	}
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano())
+	}
+
+	p := generate(g, *size, *seed)
+	if err := writeInput(*out, p.input); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "seed: %d\n", *seed)
+	fmt.Fprintf(os.Stderr, "part 1: %d\n", p.part1)
+	fmt.Fprintf(os.Stderr, "part 2: %d\n", p.part2)
+	return nil
+}
+
+// writeInput writes the input to the named file, or stdout if fname is empty
+func writeInput(fname string, input []byte) error {
+	if fname == "" {
+		if _, err := os.Stdout.Write(input); err != nil {
+			return fmt.Errorf("error writing input: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(fname, input, 0o644); err != nil {
+		return fmt.Errorf("error writing file [%s]: %w", fname, err)
+	}
+	return nil
+}
+
+// generate makes a puzzle of the size, the same one every time for the same seed
+func generate(g generator, size int, seed uint64) puzzle {
+	return g.generate(rand.New(rand.NewPCG(seed, seed)), size)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/result"
+)
+
+func TestGeneratorsAreDeterministic(t *testing.T) {
+	for day, g := range generators {
+		a := generate(g, 20, 42)
+		b := generate(g, 20, 42)
+		if !bytes.Equal(a.input, b.input) || a.part1 != b.part1 || a.part2 != b.part2 {
+			t.Errorf("day %d: the same seed made different puzzles", day)
+		}
+		if c := generate(g, 20, 43); bytes.Equal(a.input, c.input) {
+			t.Errorf("day %d: different seeds made the same input", day)
+		}
+		if len(a.input) == 0 || a.input[len(a.input)-1] != '\n' {
+			t.Errorf("day %d: input doesn't end with a newline", day)
+		}
+	}
+}
+
+// Days whose solution doesn't agree with its generator yet, and why
+var unsolvedDays = map[int]string{
+	10: "the solution doesn't count trails yet, the README lists the day as unsolved",
+}
+
+func TestSolutionsMatchGenerators(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every day")
+	}
+	root, err := repoRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, day := range slices.Sorted(maps.Keys(generators)) {
+		t.Run(fmt.Sprintf("day %02d", day), func(t *testing.T) {
+			if reason, ok := unsolvedDays[day]; ok {
+				t.Skip(reason)
+			}
+			dir := t.TempDir()
+			bin := filepath.Join(dir, "solution")
+			build := exec.Command("go", "build", "-o", bin, ".")
+			build.Dir = dayDir(root, day)
+			if out, err := build.CombinedOutput(); err != nil {
+				t.Fatalf("error building: %v\n%s", err, out)
+			}
+
+			for seed := uint64(1); seed <= 3; seed++ {
+				p := generate(generators[day], 20, seed)
+				// Days being worked on read example.txt rather than input.txt
+				for _, name := range []string{"input.txt", "example.txt"} {
+					if err := os.WriteFile(filepath.Join(dir, name), p.input, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				cmd := exec.Command(bin, "-output", result.FormatNDJSON, "-log-level", "error")
+				cmd.Dir = dir
+				var stderr bytes.Buffer
+				cmd.Stderr = &stderr
+				out, err := cmd.Output()
+				if err != nil {
+					t.Fatalf("seed %d: error running: %v\n%s", seed, err, stderr.Bytes())
+				}
+
+				// Days that only solve one part report only that part
+				want := map[int]int{1: p.part1, 2: p.part2}
+				var parts int
+				dec := json.NewDecoder(bytes.NewReader(out))
+				for dec.More() {
+					var rec result.Record
+					if err := dec.Decode(&rec); err != nil {
+						t.Fatalf("seed %d: %v", seed, err)
+					}
+					if rec.Error != "" || rec.Answer != strconv.Itoa(want[rec.Part]) {
+						t.Errorf("seed %d part %d: got %q, error %q, want %d", seed, rec.Part, rec.Answer, rec.Error, want[rec.Part])
+					}
+					parts++
+				}
+				if parts == 0 {
+					t.Errorf("seed %d: no parts reported", seed)
+				}
+			}
+		})
+	}
+}
+
+func TestReferenceAnswers(t *testing.T) {
+	// The examples from the puzzles, worked by the code the generators find their answers with
+	t.Run("day 02", func(t *testing.T) {
+		reports := [][]int{{7, 6, 4, 2, 1}, {1, 2, 7, 8, 9}, {9, 7, 6, 2, 1}, {1, 3, 2, 4, 5}, {8, 6, 4, 4, 1}, {1, 3, 6, 7, 9}}
+		var safe, dampened int
+		for _, r := range reports {
+			if safeLevels(r) {
+				safe++
+			}
+			if safeDampened(r) {
+				dampened++
+			}
+		}
+		if safe != 2 || dampened != 4 {
+			t.Errorf("got %d and %d, want 2 and 4", safe, dampened)
+		}
+	})
+
+	t.Run("day 06", func(t *testing.T) {
+		grid := toGrid("....#.....\n.........#\n..........\n..#.......\n.......#..\n..........\n.#..^.....\n........#.\n#.........\n......#...")
+		visited, _ := walkGuard(grid, 4, 6)
+		var loops int
+		for c := range visited {
+			if c == [2]int{4, 6} {
+				continue
+			}
+			grid[c[1]][c[0]] = '#'
+			if _, loop := walkGuard(grid, 4, 6); loop {
+				loops++
+			}
+			grid[c[1]][c[0]] = '.'
+		}
+		if len(visited) != 41 || loops != 6 {
+			t.Errorf("got %d and %d, want 41 and 6", len(visited), loops)
+		}
+	})
+
+	t.Run("day 07", func(t *testing.T) {
+		eqs := map[int][]int{190: {10, 19}, 3267: {81, 40, 27}, 83: {17, 5}, 156: {15, 6}, 7290: {6, 8, 6, 15},
+			161011: {16, 10, 13}, 192: {17, 8, 14}, 21037: {9, 7, 18, 13}, 292: {11, 6, 16, 20}}
+		var part1, part2 int
+		for answer, vals := range eqs {
+			if solvableEquation(answer, vals[0], vals[1:], false) {
+				part1 += answer
+			}
+			if solvableEquation(answer, vals[0], vals[1:], true) {
+				part2 += answer
+			}
+		}
+		if part1 != 3749 || part2 != 11387 {
+			t.Errorf("got %d and %d, want 3749 and 11387", part1, part2)
+		}
+	})
+
+	t.Run("day 09", func(t *testing.T) {
+		var disk []int
+		for i, c := range "2333133121414131402" {
+			for range int(c - '0') {
+				if i%2 == 0 {
+					disk = append(disk, i/2)
+				} else {
+					disk = append(disk, -1)
+				}
+			}
+		}
+		blocks := diskChecksum(compactBlocks(append([]int{}, disk...)))
+		files := diskChecksum(compactFiles(disk, 10))
+		if blocks != 1928 || files != 2858 {
+			t.Errorf("got %d and %d, want 1928 and 2858", blocks, files)
+		}
+	})
+
+	t.Run("day 10", func(t *testing.T) {
+		rows := toGrid("89010123\n78121874\n87430965\n96549874\n45678903\n32019012\n01329801\n10456732")
+		grid := make([][]int, len(rows))
+		for y, row := range rows {
+			for _, c := range row {
+				grid[y] = append(grid[y], int(c-'0'))
+			}
+		}
+		var score, rating int
+		for y, row := range grid {
+			for x, h := range row {
+				if h == 0 {
+					peaks := make(map[[2]int]bool)
+					rating += countTrails(grid, x, y, peaks)
+					score += len(peaks)
+				}
+			}
+		}
+		if score != 36 || rating != 81 {
+			t.Errorf("got %d and %d, want 36 and 81", score, rating)
+		}
+	})
+}
+
+// toGrid splits the lines of text into rows of bytes
+func toGrid(s string) [][]byte {
+	var grid [][]byte
+	for _, line := range strings.Split(s, "\n") {
+		grid = append(grid, []byte(line))
+	}
+	return grid
+}
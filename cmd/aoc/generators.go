@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/digits"
+	"github.com/hannahapuan/advent-of-code-2024/internal/input"
+)
+
+// puzzle is a generated input with the answers to both of its parts
+type puzzle struct {
+	input        []byte
+	part1, part2 int
+}
+
+// generator makes a random input for a day, with its size roughly in the units described
+type generator struct {
+	sizeUsage   string // What the size counts, shown in the help
+	defaultSize int    // Size of the real puzzle input
+	generate    func(r *rand.Rand, size int) puzzle
+}
+
+// Generators for each day with an input format in the repo
+var generators = map[int]generator{
+	1:  {sizeUsage: "lines", defaultSize: 1000, generate: genLocationLists},
+	2:  {sizeUsage: "reports", defaultSize: 1000, generate: genReports},
+	3:  {sizeUsage: "instructions", defaultSize: 700, generate: genCorruptedMemory},
+	4:  {sizeUsage: "grid side", defaultSize: 140, generate: genWordSearch},
+	5:  {sizeUsage: "updates", defaultSize: 200, generate: genPrintQueue},
+	6:  {sizeUsage: "grid side", defaultSize: 130, generate: genGuardMap},
+	7:  {sizeUsage: "equations", defaultSize: 850, generate: genEquations},
+	8:  {sizeUsage: "grid side", defaultSize: 50, generate: genAntennaMap},
+	9:  {sizeUsage: "files", defaultSize: 10000, generate: genDiskMap},
+	10: {sizeUsage: "grid side", defaultSize: 50, generate: genTopographicMap},
+}
+
+// Movement deltas to the four orthogonal neighbours, clockwise starting up
+var orthogonal = [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// genLocationLists makes two columns of location IDs (day 01)
+// Some IDs are drawn from a small pool so the right list repeats left IDs, as the similarity score needs
+func genLocationLists(r *rand.Rand, size int) puzzle {
+	pool := make([]int, max(size/10, 1))
+	for i := range pool {
+		pool[i] = 10000 + r.IntN(90000)
+	}
+	id := func() int {
+		if r.IntN(2) == 0 {
+			return pool[r.IntN(len(pool))]
+		}
+		return 10000 + r.IntN(90000)
+	}
+
+	var p puzzle
+	var buf bytes.Buffer
+	left, right := make([]int, size), make([]int, size)
+	for i := range size {
+		left[i], right[i] = id(), id()
+		fmt.Fprintf(&buf, "%d   %d\n", left[i], right[i])
+	}
+	p.input = buf.Bytes()
+
+	// Part 1 pairs the lists up in sorted order, part 2 weighs each left ID by how often it's on the right
+	counts := make(map[int]int)
+	for _, v := range right {
+		counts[v]++
+	}
+	for _, v := range left {
+		p.part2 += v * counts[v]
+	}
+	slices.Sort(left)
+	slices.Sort(right)
+	for i := range left {
+		p.part1 += max(left[i]-right[i], right[i]-left[i])
+	}
+	return p
+}
+
+// genReports makes reports of levels (day 02)
+// A third of the reports are safe, a third have a single bad level and the rest are random
+func genReports(r *rand.Rand, size int) puzzle {
+	var p puzzle
+	var buf bytes.Buffer
+	for range size {
+		n := 5 + r.IntN(4)
+		levels := make([]int, n)
+		switch r.IntN(3) {
+		case 0, 1:
+			// Steadily increasing or decreasing, by one to three
+			sign := 1 - 2*r.IntN(2)
+			levels[0] = 10 + r.IntN(80)
+			for i := 1; i < n; i++ {
+				levels[i] = levels[i-1] + sign*(1+r.IntN(3))
+			}
+			if r.IntN(2) == 0 {
+				levels[r.IntN(n)] += 1 + r.IntN(9) // One bad level
+			}
+		default:
+			for i := range levels {
+				levels[i] = 1 + r.IntN(99)
+			}
+		}
+
+		if safeLevels(levels) {
+			p.part1++
+		}
+		if safeDampened(levels) {
+			p.part2++
+		}
+		buf.WriteString(input.JoinInts(levels, " ") + "\n")
+	}
+	p.input = buf.Bytes()
+	return p
+}
+
+// safeLevels checks if the levels all increase or all decrease, by one to three at a time
+func safeLevels(levels []int) bool {
+	sign := 0
+	for i := 1; i < len(levels); i++ {
+		d := levels[i] - levels[i-1]
+		if d == 0 || d < -3 || d > 3 || (sign != 0 && (d > 0) != (sign > 0)) {
+			return false
+		}
+		sign = d
+	}
+	return true
+}
+
+// safeDampened checks if the levels are safe with at most one of them removed
+func safeDampened(levels []int) bool {
+	for i := range levels {
+		if safeLevels(slices.Delete(slices.Clone(levels), i, i+1)) {
+			return true
+		}
+	}
+	return safeLevels(levels)
+}
+
+// Near misses of instructions that a corrupted memory must not execute
+var corruptInstructions = []string{
+	"mul(4*", "mul(6,9!", "mul[3,7]", "mul ( 2 , 4 )", "mul(1234,5)", "mul(,5)", "mul(5,)",
+	"do(", "don't(", "do_not()", "mul(32,64]", "?mul(8,5", "mul(-3,4)",
+}
+
+// Characters of the noise between instructions, without brackets so noise can't complete an instruction
+const memoryNoise = "abcdefghijklmnopqrstuvwxyz!@#$%^&*-+=[]{}<>,;:'?/|~ \n"
+
+// genCorruptedMemory makes corrupted memory with mul, do and don't instructions hidden in noise (day 03)
+// The answers follow from the instructions written, with part 2 skipping the muls after a don't()
+func genCorruptedMemory(r *rand.Rand, size int) puzzle {
+	var p puzzle
+	var buf bytes.Buffer
+	enabled := true
+	for range size {
+		for range r.IntN(8) {
+			buf.WriteByte(memoryNoise[r.IntN(len(memoryNoise))])
+		}
+
+		switch n := r.IntN(10); {
+		case n < 6:
+			a, b := 1+r.IntN(999), 1+r.IntN(999)
+			fmt.Fprintf(&buf, "mul(%d,%d)", a, b)
+			p.part1 += a * b
+			if enabled {
+				p.part2 += a * b
+			}
+		case n < 7:
+			buf.WriteString("do()")
+			enabled = true
+		case n < 8:
+			buf.WriteString("don't()")
+			enabled = false
+		default:
+			buf.WriteString(corruptInstructions[r.IntN(len(corruptInstructions))])
+		}
+	}
+	buf.WriteByte('\n')
+	p.input = buf.Bytes()
+	return p
+}
+
+// genWordSearch makes a square grid of the letters of XMAS (day 04)
+func genWordSearch(r *rand.Rand, size int) puzzle {
+	const letters = "XMAS"
+	grid := make([][]byte, size)
+	for y := range grid {
+		grid[y] = make([]byte, size)
+		for x := range grid[y] {
+			grid[y][x] = letters[r.IntN(len(letters))]
+		}
+	}
+
+	at := func(x, y int) byte {
+		if y < 0 || y >= size || x < 0 || x >= size {
+			return 0
+		}
+		return grid[y][x]
+	}
+
+	var p puzzle
+	for y := range size {
+		for x := range size {
+			// Part 1 reads XMAS in any of the eight directions
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if dx == 0 && dy == 0 {
+						continue
+					}
+					found := true
+					for i := range len(letters) {
+						found = found && at(x+i*dx, y+i*dy) == letters[i]
+					}
+					if found {
+						p.part1++
+					}
+				}
+			}
+
+			// Part 2 finds two MAS crossing on an A
+			if grid[y][x] != 'A' {
+				continue
+			}
+			diag1 := string([]byte{at(x-1, y-1), at(x+1, y+1)})
+			diag2 := string([]byte{at(x+1, y-1), at(x-1, y+1)})
+			if (diag1 == "MS" || diag1 == "SM") && (diag2 == "MS" || diag2 == "SM") {
+				p.part2++
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, row := range grid {
+		buf.Write(row)
+		buf.WriteByte('\n')
+	}
+	p.input = buf.Bytes()
+	return p
+}
+
+// genPrintQueue makes page ordering rules and update lists (day 05)
+// The pages have a hidden order and every pair of pages gets a rule, so the correct order of an
+// update is its pages in the hidden order. Half of the updates are written in that order.
+func genPrintQueue(r *rand.Rand, size int) puzzle {
+	const pages = 49
+	order := r.Perm(90)[:pages] // Two digit page numbers, in their hidden order
+	for i := range order {
+		order[i] += 10
+	}
+
+	var buf bytes.Buffer
+	rules := make([][2]int, 0, pages*(pages-1)/2)
+	for i := range order {
+		for j := i + 1; j < pages; j++ {
+			rules = append(rules, [2]int{order[i], order[j]})
+		}
+	}
+	r.Shuffle(len(rules), func(i, j int) { rules[i], rules[j] = rules[j], rules[i] })
+	for _, rule := range rules {
+		fmt.Fprintf(&buf, "%d|%d\n", rule[0], rule[1])
+	}
+	buf.WriteByte('\n')
+
+	var p puzzle
+	for range size {
+		// An odd number of pages so there is a middle one
+		picked := r.Perm(pages)[:5+2*r.IntN(10)]
+		slices.Sort(picked)
+		update := make([]int, len(picked))
+		for i, idx := range picked {
+			update[i] = order[idx]
+		}
+		middle := update[len(update)/2]
+
+		if r.IntN(2) == 0 {
+			p.part1 += middle
+		} else {
+			for slices.Equal(picked, slices.Sorted(slices.Values(picked))) {
+				r.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+			}
+			for i, idx := range picked {
+				update[i] = order[idx]
+			}
+			p.part2 += middle
+		}
+		buf.WriteString(input.JoinInts(update, ",") + "\n")
+	}
+	p.input = buf.Bytes()
+	return p
+}
+
+// genGuardMap makes a square map of obstructions with a guard facing up (day 06)
+// The answers are found by walking the guard, and for part 2 trying an obstruction on every cell of the walk
+func genGuardMap(r *rand.Rand, size int) puzzle {
+	grid := make([][]byte, size)
+	for y := range grid {
+		grid[y] = make([]byte, size)
+		for x := range grid[y] {
+			grid[y][x] = '.'
+			if r.IntN(12) == 0 {
+				grid[y][x] = '#'
+			}
+		}
+	}
+	// Start the guard in the middle half of the map, so it has some way to go
+	gx, gy := size/4+r.IntN(size/2+1), size/4+r.IntN(size/2+1)
+	gx, gy = min(gx, size-1), min(gy, size-1)
+	grid[gy][gx] = '^'
+
+	var p puzzle
+	visited, _ := walkGuard(grid, gx, gy)
+	p.part1 = len(visited)
+	for c := range visited {
+		if c == [2]int{gx, gy} {
+			continue
+		}
+		grid[c[1]][c[0]] = '#'
+		if _, loops := walkGuard(grid, gx, gy); loops {
+			p.part2++
+		}
+		grid[c[1]][c[0]] = '.'
+	}
+
+	var buf bytes.Buffer
+	for _, row := range grid {
+		buf.Write(row)
+		buf.WriteByte('\n')
+	}
+	p.input = buf.Bytes()
+	return p
+}
+
+// walkGuard walks the guard from the start until it leaves the map or loops
+// Returns the cells visited and whether the guard was stuck in a loop
+func walkGuard(grid [][]byte, x, y int) (map[[2]int]bool, bool) {
+	visited := map[[2]int]bool{{x, y}: true}
+	seen := make(map[[3]int]bool) // Positions and directions, to spot a loop
+	d := 0
+	for {
+		if seen[[3]int{x, y, d}] {
+			return visited, true
+		}
+		seen[[3]int{x, y, d}] = true
+
+		nx, ny := x+orthogonal[d][0], y+orthogonal[d][1]
+		if ny < 0 || ny >= len(grid) || nx < 0 || nx >= len(grid[ny]) {
+			return visited, false
+		}
+		if grid[ny][nx] == '#' {
+			d = (d + 1) % len(orthogonal) // Turn right
+			continue
+		}
+		x, y = nx, ny
+		visited[[2]int{x, y}] = true
+	}
+}
+
+// Largest answer of a generated equation, so the solvers' arithmetic doesn't overflow
+const maxEquationAnswer = 1e15
+
+// genEquations makes calibration equations (day 07)
+// Most answers are made by applying random operators to the values, the rest are random. The answers
+// are found with a search, since an equation built with || may also be solvable without it.
+func genEquations(r *rand.Rand, size int) puzzle {
+	var p puzzle
+	var buf bytes.Buffer
+	for range size {
+		var vals []int
+		answer := 0
+		for answer == 0 || answer > maxEquationAnswer {
+			vals = make([]int, 2+r.IntN(10))
+			for i := range vals {
+				vals[i] = 1 + r.IntN(99)
+			}
+			answer = vals[0]
+			for _, v := range vals[1:] {
+				switch r.IntN(3) {
+				case 0:
+					answer += v
+				case 1:
+					answer *= v
+				default:
+					answer = int(digits.Concat(int64(answer), int64(v)))
+				}
+				if answer > maxEquationAnswer {
+					break
+				}
+			}
+		}
+		if r.IntN(3) == 0 {
+			answer += 1 + r.IntN(100)
+		}
+
+		if solvableEquation(answer, vals[0], vals[1:], false) {
+			p.part1 += answer
+		}
+		if solvableEquation(answer, vals[0], vals[1:], true) {
+			p.part2 += answer
+		}
+		fmt.Fprintf(&buf, "%d: %s\n", answer, input.JoinInts(vals, " "))
+	}
+	p.input = buf.Bytes()
+	return p
+}
+
+// solvableEquation checks if + and *, and || if allowed, evaluated left to right make the answer
+// No operator makes the running total smaller, so the search stops once it's past the answer
+func solvableEquation(answer, total int, vals []int, withConcat bool) bool {
+	if total > answer {
+		return false
+	}
+	if len(vals) == 0 {
+		return total == answer
+	}
+	v, rest := vals[0], vals[1:]
+	return solvableEquation(answer, total+v, rest, withConcat) ||
+		solvableEquation(answer, total*v, rest, withConcat) ||
+		(withConcat && solvableEquation(answer, int(digits.Concat(int64(total), int64(v))), rest, withConcat))
+}
+
+// Frequencies of the antennas, as in the puzzle
+const antennaFrequencies = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// genAntennaMap makes a square map of antennas on a few frequencies (day 08)
+func genAntennaMap(r *rand.Rand, size int) puzzle {
+	grid := make([][]byte, size)
+	for y := range grid {
+		grid[y] = bytes.Repeat([]byte{'.'}, size)
+	}
+
+	antennas := make(map[byte][][2]int)
+	for range max(size*size/50, 2) {
+		x, y := r.IntN(size), r.IntN(size)
+		if grid[y][x] != '.' {
+			continue
+		}
+		f := antennaFrequencies[r.IntN(min(len(antennaFrequencies), 1+size/2))]
+		grid[y][x] = f
+		antennas[f] = append(antennas[f], [2]int{x, y})
+	}
+
+	// Part 1 puts an antinode beyond each antenna of a pair, part 2 at every step along the pair's line
+	inBounds := func(x, y int) bool { return x >= 0 && x < size && y >= 0 && y < size }
+	antinodes, harmonics := make(map[[2]int]bool), make(map[[2]int]bool)
+	for _, as := range antennas {
+		for _, a := range as {
+			for _, b := range as {
+				if a == b {
+					continue
+				}
+				dx, dy := a[0]-b[0], a[1]-b[1]
+				if inBounds(a[0]+dx, a[1]+dy) {
+					antinodes[[2]int{a[0] + dx, a[1] + dy}] = true
+				}
+				for x, y := a[0], a[1]; inBounds(x, y); x, y = x+dx, y+dy {
+					harmonics[[2]int{x, y}] = true
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, row := range grid {
+		buf.Write(row)
+		buf.WriteByte('\n')
+	}
+	return puzzle{input: buf.Bytes(), part1: len(antinodes), part2: len(harmonics)}
+}
+
+// genDiskMap makes a dense disk map of alternating file and free space lengths (day 09)
+// The answers come from compacting the disk block by block, and then file by file
+func genDiskMap(r *rand.Rand, size int) puzzle {
+	var buf bytes.Buffer
+	var disk []int // File ID of each block, -1 for free space
+	for id := range size {
+		n := 1 + r.IntN(9)
+		buf.WriteByte(byte('0' + n))
+		for range n {
+			disk = append(disk, id)
+		}
+		if id == size-1 {
+			break
+		}
+		free := r.IntN(10)
+		buf.WriteByte(byte('0' + free))
+		for range free {
+			disk = append(disk, -1)
+		}
+	}
+	buf.WriteByte('\n')
+
+	return puzzle{
+		input: buf.Bytes(),
+		part1: diskChecksum(compactBlocks(slices.Clone(disk))),
+		part2: diskChecksum(compactFiles(disk, size)),
+	}
+}
+
+// compactBlocks moves blocks one at a time from the end of the disk to the leftmost free space
+func compactBlocks(disk []int) []int {
+	for i, j := 0, len(disk)-1; i < j; {
+		switch {
+		case disk[i] >= 0:
+			i++
+		case disk[j] < 0:
+			j--
+		default:
+			disk[i], disk[j] = disk[j], -1
+		}
+	}
+	return disk
+}
+
+// compactFiles moves whole files, highest ID first, to the leftmost free space before them big enough to hold them
+func compactFiles(disk []int, files int) []int {
+	for id := files - 1; id >= 0; id-- {
+		start := slices.Index(disk, id)
+		n := 0
+		for start+n < len(disk) && disk[start+n] == id {
+			n++
+		}
+
+		for i, run := 0, 0; i < start; i++ {
+			if disk[i] >= 0 {
+				run = 0
+				continue
+			}
+			if run++; run == n {
+				for k := range n {
+					disk[i-n+1+k], disk[start+k] = id, -1
+				}
+				break
+			}
+		}
+	}
+	return disk
+}
+
+// diskChecksum adds up each block's position times its file ID
+func diskChecksum(disk []int) int {
+	var sum int
+	for i, id := range disk {
+		if id > 0 {
+			sum += i * id
+		}
+	}
+	return sum
+}
+
+// genTopographicMap makes a square map of heights with hiking trails climbing from 0 to 9 (day 10)
+// Trails are laid as random walks climbing by one, over a background of random heights
+func genTopographicMap(r *rand.Rand, size int) puzzle {
+	grid := make([][]int, size)
+	for y := range grid {
+		grid[y] = make([]int, size)
+		for x := range grid[y] {
+			grid[y][x] = r.IntN(10)
+		}
+	}
+	for range max(size*size/40, 1) {
+		layTrail(r, grid, r.IntN(size), r.IntN(size))
+	}
+
+	// Count, for every cell, the peaks reachable and the trails to them by climbing one at a time
+	var p puzzle
+	for y := range size {
+		for x := range size {
+			if grid[y][x] != 0 {
+				continue
+			}
+			peaks := make(map[[2]int]bool)
+			p.part2 += countTrails(grid, x, y, peaks)
+			p.part1 += len(peaks)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, row := range grid {
+		for _, h := range row {
+			buf.WriteByte(byte('0' + h))
+		}
+		buf.WriteByte('\n')
+	}
+	p.input = buf.Bytes()
+	return p
+}
+
+// layTrail writes heights 0 to 9 along a random walk from the cell that never steps back on itself
+// The walk stops early if it's boxed in
+func layTrail(r *rand.Rand, grid [][]int, x, y int) {
+	walked := make(map[[2]int]bool)
+	for h := range 10 {
+		grid[y][x] = h
+		walked[[2]int{x, y}] = true
+
+		var next [][2]int
+		for _, d := range orthogonal {
+			nx, ny := x+d[0], y+d[1]
+			if ny >= 0 && ny < len(grid) && nx >= 0 && nx < len(grid[ny]) && !walked[[2]int{nx, ny}] {
+				next = append(next, [2]int{nx, ny})
+			}
+		}
+		if len(next) == 0 {
+			return
+		}
+		n := next[r.IntN(len(next))]
+		x, y = n[0], n[1]
+	}
+}
+
+// countTrails counts the trails climbing from the cell to a 9, adding the 9s reached to peaks
+func countTrails(grid [][]int, x, y int, peaks map[[2]int]bool) int {
+	if grid[y][x] == 9 {
+		peaks[[2]int{x, y}] = true
+		return 1
+	}
+	var trails int
+	for _, d := range orthogonal {
+		nx, ny := x+d[0], y+d[1]
+		if ny >= 0 && ny < len(grid) && nx >= 0 && nx < len(grid[ny]) && grid[ny][nx] == grid[y][x]+1 {
+			trails += countTrails(grid, nx, ny, peaks)
+		}
+	}
+	return trails
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+)
+
+// Days whose solution can export a graph with -graph
+var graphDays = map[int]bool{
+	5: true, // Page ordering rules
+}
+
+// runGraph exports a day's graph as Graphviz DOT
+//
+//	aoc graph --day 5 [--out rules.dot]
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	day := fs.Int("day", 0, "day to export the graph of")
+	out := fs.String("out", "", "file to write the DOT export to, defaults to stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !graphDays[*day] {
+		return fmt.Errorf("day %d has no graph to export", *day)
+	}
+
+	dayArgs := []string{"-graph"}
+	if *out != "" {
+		// The day runs from its own directory, so make the output path absolute
+		path, err := filepath.Abs(*out)
+		if err != nil {
+			return fmt.Errorf("error resolving output path [%s]: %w", *out, err)
+		}
+		dayArgs = append(dayArgs, "-graph-out", path)
+	}
+	return runDay(*day, dayArgs...)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGraphUnknownDay(t *testing.T) {
+	err := runGraph([]string{"--day", "4"})
+	if err == nil || !strings.Contains(err.Error(), "day 4 has no graph") {
+		t.Fatalf("got error %v, want day 4 has no graph", err)
+	}
+}
+
+func TestRunGraph(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs day 05 with the go tool")
+	}
+	out := filepath.Join(t.TempDir(), "rules.dot")
+	if err := runGraph([]string{"--day", "5", "--out", out}); err != nil {
+		t.Fatal(err)
+	}
+
+	dot, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"digraph rules {", "\t97 -> 75;", "digraph update_0 {"} {
+		if !strings.Contains(string(dot), want) {
+			t.Errorf("missing %q in\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// Advent of Code 2024 - helper tool for working with the daily solutions
+//
+// Usage:
+//
+//	aoc <command> [flags]
+
+// command is a subcommand of the aoc tool
+type command struct {
+	usage string                    // One line description shown in the help
+	run   func(args []string) error // Runs the command with the arguments that follow its name
+}
+
+var commands = map[string]command{
+	"gen":   {usage: "generate a random input for a day, with its answers", run: runGen},
+	"graph": {usage: "export a day's graph as Graphviz DOT", run: runGraph},
+	"new":   {usage: "generate the package for a new day from a template", run: runNew},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Printf("unknown command %q\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// printUsage lists the available commands
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("usage: aoc <command> [flags]")
+	fmt.Println()
+	fmt.Println("commands:")
+	for _, name := range names {
+		fmt.Printf("  %-8s %s\n", name, commands[name].usage)
+	}
+}
+
+// repoRoot returns the root of the repository: the nearest directory above the working directory holding a go.mod
+func repoRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("error finding repository root: no go.mod found")
+		}
+		dir = parent
+	}
+}
+
+// dayDir returns the directory holding a day's solution, e.g. 05 for day 5
+func dayDir(root string, day int) string {
+	return filepath.Join(root, fmt.Sprintf("%02d", day))
+}
+
+// runDay runs a day's solution from its own directory, so it picks up its input files, with the given flags
+func runDay(day int, args ...string) error {
+	root, err := repoRoot()
+	if err != nil {
+		return err
+	}
+
+	dir := dayDir(root, day)
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("error finding day %d: %w", day, err)
+	}
+
+	cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running day %d: %w", day, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Variants of the generated solution, one for each style of input already in the repo
+// Each maps to a small example input the generated test starts with
+var variants = map[string]string{
+	"lines": "first line\nsecond line\n", // Plain lines of text
+	"grid":  "..#\n#..\n.#.\n",           // A rectangular grid of runes, like days 04, 06, 08 and 10
+	"ints":  "7 6 4 2 1\n1 2 7 8 9\n",    // Whitespace separated integers on each line, like days 01 and 02
+}
+
+// Status shown in the README for a day that hasn't been solved yet
+const unsolvedStatus = "❌"
+
+// templateData is what the templates are rendered with
+type templateData struct {
+	Day     int
+	Example string // Example input for the generated test
+}
+
+// runNew generates the package for a new day from a template
+//
+//	aoc new --day 11 [--variant lines|grid|ints]
+func runNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	day := fs.Int("day", 0, "day to generate")
+	variant := fs.String("variant", "lines", "style of input: lines, grid or ints")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *day < 1 || *day > 25 {
+		return fmt.Errorf("day must be between 1 and 25, got %d", *day)
+	}
+	example, ok := variants[*variant]
+	if !ok {
+		return fmt.Errorf("unknown variant %q, expected lines, grid or ints", *variant)
+	}
+
+	root, err := repoRoot()
+	if err != nil {
+		return err
+	}
+	dir := dayDir(root, *day)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("day %d already exists in %s", *day, dir)
+	}
+
+	files, err := renderDay(*variant, templateData{Day: *day, Example: example})
+	if err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating directory [%s]: %w", dir, err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return fmt.Errorf("error writing file [%s]: %w", path, err)
+		}
+	}
+
+	if err := addReadmeStatus(filepath.Join(root, "README.md"), *day); err != nil {
+		return err
+	}
+
+	fmt.Printf("created %s from the %s template\n", dir, *variant)
+	return nil
+}
+
+// renderDay renders the files of a new day, keyed by file name
+func renderDay(variant string, data templateData) (map[string][]byte, error) {
+	sources := map[string]string{
+		"main.go":      "templates/" + variant + ".go.tmpl",
+		"main_test.go": "templates/main_test.go.tmpl",
+	}
+
+	files := map[string][]byte{
+		"notes.md": nil, // Empty, for notes taken while solving
+	}
+	for name, src := range sources {
+		tmpl, err := template.ParseFS(templateFS, src)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template [%s]: %w", src, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("error rendering template [%s]: %w", src, err)
+		}
+		out, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("error formatting %s rendered from [%s]: %w", name, src, err)
+		}
+		files[name] = out
+	}
+	return files, nil
+}
+
+// addReadmeStatus lists the day in the README as unsolved, unless it is already listed
+// The day is added to the list of days in order
+func addReadmeStatus(fname string, day int) error {
+	content, err := os.ReadFile(fname)
+	if err != nil {
+		return fmt.Errorf("error opening file [%s]: %w", fname, err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	at := -1 // Index to insert the day at
+	for i, line := range lines {
+		var listed int
+		if _, err := fmt.Sscanf(line, "- Day %d:", &listed); err != nil {
+			continue
+		}
+		if listed == day {
+			return nil
+		}
+		// After every day before this one, or before the first day after it
+		if listed < day || at < 0 {
+			at = i
+			if listed < day {
+				at++
+			}
+		}
+	}
+	if at < 0 {
+		return errors.New("error updating README: no list of days found")
+	}
+
+	status := fmt.Sprintf("- Day %02d: %s", day, unsolvedStatus)
+	lines = append(lines[:at], append([]string{status}, lines[at:]...)...)
+	if err := os.WriteFile(fname, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		return fmt.Errorf("error writing file [%s]: %w", fname, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// modulePath is the path of the module the rendered days import from
+const modulePath = "github.com/hannahapuan/advent-of-code-2024"
+
+func TestRenderedDaysBuildAndPass(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool on every variant")
+	}
+	for variant, example := range variants {
+		t.Run(variant, func(t *testing.T) {
+			files, err := renderDay(variant, templateData{Day: 11, Example: example})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Render into a module of its own that uses this one from disk. Its path is below this
+			// module's, so the day can still import the internal packages.
+			root, err := filepath.Abs(filepath.Join("..", ".."))
+			if err != nil {
+				t.Fatal(err)
+			}
+			files["go.mod"] = []byte(fmt.Sprintf("module %[1]s/day-%[2]s\n\ngo 1.23.1\n\nrequire %[1]s v0.0.0\n\nreplace %[1]s => %[3]q\n",
+				modulePath, variant, root))
+			dir := t.TempDir()
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, args := range [][]string{{"vet", "."}, {"test", "."}} {
+				cmd := exec.Command("go", args...)
+				cmd.Dir = dir
+				cmd.Env = append(os.Environ(), "GOWORK=off")
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("go %s: %s\n%s", strings.Join(args, " "), err, out)
+				}
+			}
+		})
+	}
+}
+
+func TestAddReadmeStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		day  int
+		want string
+	}{
+		{
+			name: "after the last day",
+			text: "# AoC\n- Day 01: ⭐⭐\n- Day 02: ⭐\n\nmore\n",
+			day:  3,
+			want: "# AoC\n- Day 01: ⭐⭐\n- Day 02: ⭐\n- Day 03: ❌\n\nmore\n",
+		},
+		{
+			name: "between days",
+			text: "- Day 01: ⭐⭐\n- Day 12: ❌\n",
+			day:  11,
+			want: "- Day 01: ⭐⭐\n- Day 11: ❌\n- Day 12: ❌\n",
+		},
+		{
+			name: "already listed",
+			text: "- Day 01: ⭐⭐\n- Day 11: ❌\n",
+			day:  11,
+			want: "- Day 01: ⭐⭐\n- Day 11: ❌\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fname := filepath.Join(t.TempDir(), "README.md")
+			if err := os.WriteFile(fname, []byte(tt.text), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := addReadmeStatus(fname, tt.day); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(fname)
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
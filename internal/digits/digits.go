@@ -0,0 +1,48 @@
+// Package digits works with the decimal digits of integers without formatting them as strings
+package digits
+
+import "math"
+
+// Count returns the number of decimal digits of a non-negative integer, 1 for 0
+func Count(n int64) int {
+	count := 1
+	for n >= 10 {
+		n /= 10
+		count++
+	}
+	return count
+}
+
+// Pow10 returns 10 to the power of k, for 0 <= k <= 18
+func Pow10(k int) int64 {
+	p := int64(1)
+	for ; k > 0; k-- {
+		p *= 10
+	}
+	return p
+}
+
+// Split cuts a non-negative integer with an even number of digits into its left and right halves
+// e.g. 1234 => 12, 34 and 1000 => 10, 0. ok is false if the number has an odd number of digits.
+func Split(n int64) (left, right int64, ok bool) {
+	count := Count(n)
+	if count%2 != 0 {
+		return 0, 0, false
+	}
+	half := Pow10(count / 2)
+	return n / half, n % half, true
+}
+
+// Concat joins the digits of two non-negative integers
+// e.g. 12, 34 => 1234. The result overflows if it has more than 18 digits.
+func Concat(a, b int64) int64 {
+	return a*Pow10(Count(b)) + b
+}
+
+// Mul multiplies two non-negative integers, ok is false if the product overflows an int64
+func Mul(a, b int64) (product int64, ok bool) {
+	if a != 0 && b > math.MaxInt64/a {
+		return 0, false
+	}
+	return a * b, true
+}
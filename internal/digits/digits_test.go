@@ -0,0 +1,70 @@
+package digits
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	for _, n := range []int64{0, 1, 9, 10, 99, 100, 123456, math.MaxInt64} {
+		if got, want := Count(n), len(strconv.FormatInt(n, 10)); got != want {
+			t.Errorf("Count(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		n           int64
+		left, right int64
+		ok          bool
+	}{
+		{n: 1234, left: 12, right: 34, ok: true},
+		{n: 1000, left: 10, right: 0, ok: true},
+		{n: 10, left: 1, right: 0, ok: true},
+		{n: 0},
+		{n: 123},
+	}
+	for _, tt := range tests {
+		left, right, ok := Split(tt.n)
+		if left != tt.left || right != tt.right || ok != tt.ok {
+			t.Errorf("Split(%d) = %d, %d, %t, want %d, %d, %t", tt.n, left, right, ok, tt.left, tt.right, tt.ok)
+		}
+	}
+}
+
+func TestConcat(t *testing.T) {
+	tests := []struct{ a, b, want int64 }{
+		{a: 12, b: 34, want: 1234},
+		{a: 12, b: 0, want: 120},
+		{a: 0, b: 5, want: 5},
+		{a: 1, b: 100, want: 1100},
+	}
+	for _, tt := range tests {
+		if got := Concat(tt.a, tt.b); got != tt.want {
+			t.Errorf("Concat(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		a, b int64
+		want int64
+		ok   bool
+	}{
+		{a: 0, b: math.MaxInt64, want: 0, ok: true},
+		{a: 2024, b: 3, want: 6072, ok: true},
+		{a: math.MaxInt64, b: 1, want: math.MaxInt64, ok: true},
+		{a: math.MaxInt64 / 2, b: 2, want: math.MaxInt64 - 1, ok: true},
+		{a: math.MaxInt64/2 + 1, b: 2},
+		{a: 1e16, b: 2024},
+	}
+	for _, tt := range tests {
+		got, ok := Mul(tt.a, tt.b)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Mul(%d, %d) = %d, %t, want %d, %t", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field is a piece of a line along with where it starts
+type Field struct {
+	Text   string
+	Column int // 1-based byte offset of the field in its line
+}
+
+// Split splits the line around each instance of sep, keeping the column of every field
+func Split(line, sep string) []Field {
+	fields := make([]Field, 0)
+	col := 1
+	for _, text := range strings.Split(line, sep) {
+		fields = append(fields, Field{Text: text, Column: col})
+		col += len(text) + len(sep)
+	}
+	return fields
+}
+
+// Fields splits the line around runs of whitespace, keeping the column of every field
+func Fields(line string) []Field {
+	fields := make([]Field, 0)
+	start := -1
+	for i, r := range line {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			fields = append(fields, Field{Text: line[start:i], Column: start + 1})
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, Field{Text: line[start:], Column: start + 1})
+	}
+	return fields
+}
+
+// JoinInts formats the integers separated by sep, the way inputs list them, e.g. 1,2,3
+func JoinInts(vals []int, sep string) string {
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, sep)
+}
+
+// Int parses the field as a base 10 integer, with any error marked at the field's column
+func (f Field) Int() (int, error) {
+	v, err := strconv.Atoi(f.Text)
+	if err != nil {
+		return 0, At(f.Column, fmt.Errorf("expected an integer, found %q", f.Text))
+	}
+	return v, nil
+}
+
+// columnError marks an error in parsing a line as being at a column of the line
+type columnError struct {
+	column int
+	err    error
+}
+
+func (e *columnError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.column, e.err)
+}
+
+func (e *columnError) Unwrap() error {
+	return e.err
+}
+
+// At marks an error in parsing a line as being at the 1-based column
+// ReadSections moves the column into the ParseError it returns
+func At(column int, err error) error {
+	return &columnError{column: column, err: err}
+}
+
+// columnOf returns the column an error was marked at by At, 0 if it wasn't, and the error without the mark
+func columnOf(err error) (int, error) {
+	var ce *columnError
+	if errors.As(err, &ce) {
+		return ce.column, ce.err
+	}
+	return 0, err
+}
@@ -0,0 +1,21 @@
+package input
+
+import "testing"
+
+func TestJoinInts(t *testing.T) {
+	tests := []struct {
+		vals []int
+		sep  string
+		want string
+	}{
+		{vals: nil, sep: ",", want: ""},
+		{vals: []int{7}, sep: ",", want: "7"},
+		{vals: []int{75, 47, -61}, sep: ",", want: "75,47,-61"},
+		{vals: []int{1, 2, 3}, sep: " ", want: "1 2 3"},
+	}
+	for _, tt := range tests {
+		if got := JoinInts(tt.vals, tt.sep); got != tt.want {
+			t.Errorf("JoinInts(%v, %q) = %q, want %q", tt.vals, tt.sep, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Grid reads a rectangular grid of runes from normalized input, one row per line
+// Rows that aren't as wide as the first are each logged as a warning, so every one of
+// them shows up, and the first of them is returned as the error. A grid needs at least one cell.
+func Grid(r io.Reader) ([][]rune, error) {
+	grid := make([][]rune, 0)
+	var ragged error // First row that isn't as wide as the first
+	scanner := bufio.NewScanner(Normalize(r))
+	for scanner.Scan() {
+		line := scanner.Text()
+		row := []rune(line)
+		if len(grid) > 0 && len(row) != len(grid[0]) {
+			slog.Warn("ragged grid row", "line", len(grid)+1, "cells", len(row), "expected", len(grid[0]))
+			if ragged == nil {
+				ragged = &ParseError{Line: len(grid) + 1, Text: line, Err: fmt.Errorf("ragged grid: %d cells, expected %d like line 1", len(row), len(grid[0]))}
+			}
+		}
+		grid = append(grid, row)
+	}
+
+	// Check for any errors encountered during scanning
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{Line: len(grid) + 1, Err: fmt.Errorf("error reading grid: %w", err)}
+	}
+	if ragged != nil {
+		return nil, ragged
+	}
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return nil, &ParseError{Err: errors.New("empty grid")}
+	}
+	return grid, nil
+}
@@ -0,0 +1,64 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string // Rows of the grid
+		wantErr string
+	}{
+		{name: "rectangular", in: "ab\ncd\nef\n", want: []string{"ab", "cd", "ef"}},
+		{name: "normalized", in: "\xef\xbb\xbfab \r\ncd\r\n\r\n", want: []string{"ab", "cd"}},
+		{name: "runes", in: "日本\n語.\n", want: []string{"日本", "語."}},
+		{name: "ragged", in: "abc\nab\nabc\nabcd\n", wantErr: `line 2: ragged grid: 2 cells, expected 3 like line 1: "ab"`},
+		{name: "empty", in: "", wantErr: "empty grid"},
+		{name: "blank lines", in: "\n\n", wantErr: "empty grid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid, err := Grid(strings.NewReader(tt.in))
+			if tt.wantErr != "" {
+				var pe *ParseError
+				if !errors.As(err, &pe) || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want ParseError %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, row := range grid {
+				got = append(got, string(row))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got rows %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGridWarnsEveryRaggedRow(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	if _, err := Grid(strings.NewReader("abc\nab\nabc\nabcd\n")); err == nil {
+		t.Fatal("expected an error for a ragged grid")
+	}
+	for _, want := range []string{"line=2 cells=2", "line=4 cells=4"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("no warning with %q in %q", want, logs.String())
+		}
+	}
+}
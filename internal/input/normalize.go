@@ -0,0 +1,92 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// bom is the UTF-8 byte order mark some editors put at the start of a file
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizer smooths over the ways the same input can be saved on different platforms
+type normalizer struct {
+	r        *bufio.Reader
+	started  bool   // Whether the start of the input has been checked for a BOM
+	written  bool   // Whether any content has been passed on
+	afterCR  bool   // Whether the last byte read was a CR, so a following LF is part of a CRLF
+	newlines int    // Line breaks held back until it's clear they aren't trailing blank lines
+	space    []byte // Spaces and tabs held back until it's clear they aren't at the end of a line
+	pending  []byte // Bytes released but not yet returned
+	err      error  // Error from the underlying reader, returned once everything before it is
+}
+
+// Normalize returns a reader of r's input with platform differences smoothed over
+// A leading UTF-8 BOM is dropped, CRLF and lone CR line endings become LF,
+// spaces and tabs at the end of lines are trimmed and so are blank lines at the
+// end of the input. Line numbers of the remaining lines are unchanged.
+func Normalize(r io.Reader) io.Reader {
+	return &normalizer{r: bufio.NewReader(r)}
+}
+
+func (n *normalizer) Read(p []byte) (int, error) {
+	if !n.started {
+		n.started = true
+		if b, err := n.r.Peek(len(bom)); err == nil && bytes.Equal(b, bom) {
+			n.r.Discard(len(bom))
+		}
+	}
+
+	i := 0
+	for i < len(p) {
+		if len(n.pending) > 0 {
+			c := copy(p[i:], n.pending)
+			n.pending = n.pending[c:]
+			i += c
+			continue
+		}
+		if n.err != nil {
+			break
+		}
+
+		b, err := n.r.ReadByte()
+		if err != nil {
+			n.err = err
+			// Content ends with a single line break however many blank lines followed it
+			if errors.Is(err, io.EOF) && n.written && n.newlines > 0 {
+				n.pending = []byte{'\n'}
+			}
+			continue
+		}
+
+		switch {
+		case b == '\n' && n.afterCR: // Second half of a CRLF
+			n.afterCR = false
+		case b == '\r' || b == '\n':
+			n.afterCR = b == '\r'
+			n.space = n.space[:0]
+			n.newlines++
+		case b == ' ' || b == '\t':
+			n.afterCR = false
+			n.space = append(n.space, b)
+		case n.newlines == 0 && len(n.space) == 0:
+			n.afterCR = false
+			n.written = true
+			p[i] = b
+			i++
+		default:
+			// Release what was held back now that content follows it
+			n.afterCR = false
+			n.written = true
+			n.pending = append(bytes.Repeat([]byte{'\n'}, n.newlines), n.space...)
+			n.pending = append(n.pending, b)
+			n.newlines, n.space = 0, n.space[:0]
+		}
+	}
+
+	if i > 0 {
+		return i, nil
+	}
+	return 0, n.err
+}
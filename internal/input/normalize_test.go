@@ -0,0 +1,49 @@
+package input
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "unchanged", in: "ab\ncd\n", want: "ab\ncd\n"},
+		{name: "no trailing newline", in: "ab\ncd", want: "ab\ncd"},
+		{name: "byte order mark", in: "\xef\xbb\xbfab\n", want: "ab\n"},
+		{name: "byte order mark only at the start", in: "ab\n\xef\xbb\xbf\n", want: "ab\n\xef\xbb\xbf\n"},
+		{name: "crlf", in: "ab\r\ncd\r\n", want: "ab\ncd\n"},
+		{name: "lone cr", in: "ab\rcd\r", want: "ab\ncd\n"},
+		{name: "cr then crlf", in: "ab\r\r\ncd", want: "ab\n\ncd"},
+		{name: "trailing whitespace", in: "ab \t\ncd  ", want: "ab\ncd"},
+		{name: "inner whitespace", in: "a b\t c\n", want: "a b\t c\n"},
+		{name: "trailing blank lines", in: "ab\n\n \r\n\t\n", want: "ab\n"},
+		{name: "leading blank lines kept", in: "\n\nab\n", want: "\n\nab\n"},
+		{name: "inner blank lines kept", in: "ab\n\n\ncd\n", want: "ab\n\n\ncd\n"},
+		{name: "blank", in: " \n\r\n", want: ""},
+		{name: "empty", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Also read a byte at a time, so held back bytes are returned across reads
+			readers := []io.Reader{
+				Normalize(strings.NewReader(tt.in)),
+				iotest.OneByteReader(Normalize(strings.NewReader(tt.in))),
+			}
+			for _, r := range readers {
+				got, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != tt.want {
+					t.Fatalf("got %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Package input holds input reading helpers shared by the daily solutions
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Parser is a section of an input that parses its own lines
+type Parser interface {
+	// SectionName identifies the section in error messages
+	SectionName() string
+	// ParseLine parses a single line of the section, num is its 1-based line number in the input
+	ParseLine(num int, line string) error
+}
+
+// Section is a section of an input whose lines each parse into a value of type T
+type Section[T any] struct {
+	Name   string                       // Identifies the section in error messages
+	Parse  func(line string) (T, error) // Parses a single line
+	Values []T                          // Parsed lines, in input order
+	Lines  []int                        // 1-based input line number of each value
+}
+
+// NewSection creates a section that parses each of its lines with parse
+func NewSection[T any](name string, parse func(line string) (T, error)) *Section[T] {
+	return &Section[T]{Name: name, Parse: parse}
+}
+
+// SectionName identifies the section in error messages
+func (s *Section[T]) SectionName() string {
+	return s.Name
+}
+
+// ParseLine parses a line and appends its value, and where it came from, to the section
+func (s *Section[T]) ParseLine(num int, line string) error {
+	v, err := s.Parse(line)
+	if err != nil {
+		return err
+	}
+	s.Values = append(s.Values, v)
+	s.Lines = append(s.Lines, num)
+	return nil
+}
+
+// ParseError reports input that couldn't be parsed, or a section that is missing or unexpected
+type ParseError struct {
+	Section string // Name of the section being parsed, empty if the input isn't split into sections
+	Line    int    // 1-based line number in the input, 0 if the error is about the input as a whole
+	Column  int    // 1-based byte offset in the line, 0 if the error is about the whole line
+	Text    string // Text of the offending line, empty if it would be too long to be useful
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		if e.Section == "" {
+			return e.Err.Error()
+		}
+		return fmt.Sprintf("section %s: %s", e.Section, e.Err)
+	}
+
+	pos := fmt.Sprintf("line %d", e.Line)
+	if e.Column > 0 {
+		pos += fmt.Sprintf(", column %d", e.Column)
+	}
+	if e.Section != "" {
+		pos += fmt.Sprintf(" (section %s)", e.Section)
+	}
+	if e.Text == "" {
+		return fmt.Sprintf("%s: %s", pos, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %q", pos, e.Err, e.Text)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ReadSections reads an input made of sections separated by blank lines
+// Each line of the nth section is passed to the nth parser. Runs of blank
+// lines count as a single separator and blank lines at the start or end of
+// the input are ignored. It is an error for the input to have more or fewer
+// sections than there are parsers. The input is normalized first, see Normalize.
+func ReadSections(r io.Reader, parsers ...Parser) error {
+	scanner := bufio.NewScanner(Normalize(r))
+
+	section := 0       // Index of the section being read
+	inSection := false // Whether a line of the current section has been read
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		// A blank line ends the current section
+		if strings.TrimSpace(line) == "" {
+			if inSection {
+				section++
+				inSection = false
+			}
+			continue
+		}
+
+		if section >= len(parsers) {
+			return &ParseError{
+				Section: fmt.Sprintf("#%d", section+1),
+				Line:    lineNum,
+				Text:    line,
+				Err:     fmt.Errorf("unexpected section, expected %d", len(parsers)),
+			}
+		}
+
+		inSection = true
+		p := parsers[section]
+		if err := p.ParseLine(lineNum, line); err != nil {
+			col, err := columnOf(err)
+			return &ParseError{Section: p.SectionName(), Line: lineNum, Column: col, Text: line, Err: err}
+		}
+	}
+
+	// Check for errors in reading the input, such as a line too long to scan
+	if err := scanner.Err(); err != nil {
+		name := fmt.Sprintf("#%d", section+1)
+		if section < len(parsers) {
+			name = parsers[section].SectionName()
+		}
+		return &ParseError{Section: name, Line: lineNum + 1, Err: fmt.Errorf("error reading input: %w", err)}
+	}
+
+	if inSection {
+		section++
+	}
+	if section < len(parsers) {
+		return &ParseError{Section: parsers[section].SectionName(), Err: fmt.Errorf("missing section")}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSectionLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		first  []int // Input line of each value in the first section
+		second []int // Input line of each value in the second section
+	}{
+		{name: "plain", in: "a\nb\n\nc\n", first: []int{1, 2}, second: []int{4}},
+		{name: "leading blank lines", in: "\n\na\nb\n\nc\n", first: []int{3, 4}, second: []int{6}},
+		{name: "run of blank lines", in: "a\n\n \n\t\nb\nc\n", first: []int{1}, second: []int{5, 6}},
+		{name: "crlf and lone cr", in: "a\r\nb\r\rc\r", first: []int{1, 2}, second: []int{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity := func(line string) (string, error) { return line, nil }
+			first, second := NewSection("first", identity), NewSection("second", identity)
+			if err := ReadSections(strings.NewReader(tt.in), first, second); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(first.Lines, tt.first) || !reflect.DeepEqual(second.Lines, tt.second) {
+				t.Errorf("got lines %v and %v, want %v and %v", first.Lines, second.Lines, tt.first, tt.second)
+			}
+			if len(first.Values) != len(first.Lines) || len(second.Values) != len(second.Lines) {
+				t.Errorf("got %d and %d values for %d and %d lines", len(first.Values), len(second.Values), len(first.Lines), len(second.Lines))
+			}
+		})
+	}
+}
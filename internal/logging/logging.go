@@ -0,0 +1,104 @@
+// Package logging sets up the leveled logging shared by the daily solutions
+//
+// Answers are printed to stdout and everything else is logged to stderr, so
+// the output of a solution can be consumed by scripts whatever the log level.
+package logging
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// LevelTrace is below debug, for logging every step of a search or simulation
+const LevelTrace = slog.LevelDebug - 4
+
+// Log levels by the names accepted by --log-level
+var levels = map[string]slog.Level{
+	"error": slog.LevelError,
+	"info":  slog.LevelInfo,
+	"debug": slog.LevelDebug,
+	"trace": LevelTrace,
+}
+
+// Log formats accepted by --log-format
+const (
+	FormatText string = "text"
+	FormatJSON string = "json"
+)
+
+// Options are the logging flags of a solution
+type Options struct {
+	Level  string // error, info, debug or trace
+	Format string // text or json
+}
+
+// AddFlags registers --log-level and --log-format on the flag set
+func AddFlags(fs *flag.FlagSet) *Options {
+	o := &Options{}
+	fs.StringVar(&o.Level, "log-level", "info", "log level: error, info, debug or trace")
+	fs.StringVar(&o.Format, "log-format", FormatText, "log format: text or json")
+	return o
+}
+
+// Main registers the logging flags, parses the command line and sets up logging
+// Any flags of the solution itself must be registered first. A bad level or format exits with status 2,
+// as the flag package does for a bad flag.
+func Main() {
+	o := AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := o.Setup(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// Setup makes a logger writing to stderr with the chosen level and format the default logger
+func (o *Options) Setup() error {
+	logger, err := New(os.Stderr, o.Level, o.Format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// New creates a logger writing to w with the named level and format
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, ok := levels[level]
+	if !ok {
+		return nil, fmt.Errorf("unknown log level %q, expected error, info, debug or trace", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: nameTrace}
+	switch format {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q, expected %s or %s", format, FormatText, FormatJSON)
+}
+
+// nameTrace shows the trace level as TRACE rather than DEBUG-4
+func nameTrace(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey {
+		if lvl, ok := a.Value.Any().(slog.Level); ok && lvl == LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+	}
+	return a
+}
+
+// Trace logs at trace level with the default logger
+func Trace(msg string, args ...any) {
+	slog.Log(context.Background(), LevelTrace, msg, args...)
+}
+
+// Enabled checks if the default logger logs at the level, to skip building expensive log messages
+func Enabled(level slog.Level) bool {
+	return slog.Default().Enabled(context.Background(), level)
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	// Messages logged at each level, in order from the most to the least severe
+	logAll := func(l *slog.Logger) {
+		l.Error("error message")
+		l.Info("info message")
+		l.Debug("debug message")
+		l.Log(context.Background(), LevelTrace, "trace message")
+	}
+	tests := []struct {
+		level string
+		want  []string // Levels of the messages logged
+	}{
+		{level: "error", want: []string{"ERROR"}},
+		{level: "info", want: []string{"ERROR", "INFO"}},
+		{level: "debug", want: []string{"ERROR", "INFO", "DEBUG"}},
+		{level: "trace", want: []string{"ERROR", "INFO", "DEBUG", "TRACE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			var out bytes.Buffer
+			l, err := New(&out, tt.level, FormatJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logAll(l)
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				var rec struct{ Level string }
+				if err := json.Unmarshal([]byte(line), &rec); err != nil {
+					t.Fatalf("%q: %v", line, err)
+				}
+				got = append(got, rec.Level)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got levels %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceText(t *testing.T) {
+	var out bytes.Buffer
+	l, err := New(&out, "trace", FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(l)
+	defer slog.SetDefault(prev)
+
+	if !Enabled(LevelTrace) {
+		t.Fatal("trace level isn't enabled")
+	}
+	Trace("step", "x", 1)
+	if got := out.String(); !strings.Contains(got, "level=TRACE") || !strings.Contains(got, "msg=step x=1") {
+		t.Errorf("got %q, want a TRACE record", got)
+	}
+}
+
+func TestUnknownOptions(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "verbose", FormatText); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
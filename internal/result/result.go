@@ -0,0 +1,206 @@
+// Package result reports the answers of a solution as uniform records
+//
+// Every part of a run becomes a record with the day, part, answer, a hash of
+// the input, how long the part took and any error, so runs can be compared
+// by tools without scraping each day's own output.
+package result
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hannahapuan/advent-of-code-2024/internal/logging"
+)
+
+// Output formats accepted by --output
+const (
+	FormatTable  string = "table"  // Aligned columns for people, written when the run finishes
+	FormatJSON   string = "json"   // A JSON array of the records, written when the run finishes
+	FormatNDJSON string = "ndjson" // One JSON record per line, written as each part finishes
+)
+
+// Length of the input hash shown in the table
+const tableHashLen = 12
+
+// Record is the result of one part of a run
+type Record struct {
+	Day       int           `json:"day"`
+	Part      int           `json:"part"`             // 0 when the error stopped the run before any part
+	Answer    string        `json:"answer,omitempty"` // Formatted with fmt, so large answers keep every digit
+	InputHash string        `json:"input_sha256"`     // Empty if the input file couldn't be read
+	Duration  time.Duration `json:"duration_ns"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Options are the output flags of a solution
+type Options struct {
+	Format string // table, json or ndjson
+}
+
+// AddFlags registers --output on the flag set
+func AddFlags(fs *flag.FlagSet) *Options {
+	o := &Options{}
+	fs.StringVar(&o.Format, "output", FormatTable, "answer format: table, json or ndjson")
+	return o
+}
+
+// Main registers --output and the logging flags, parses the command line and starts a run of the day
+// over the named input file. Any flags of the solution itself must be registered first. A bad flag value
+// exits with status 2, as the flag package does for a bad flag.
+func Main(day int, fname string) *Run {
+	o := AddFlags(flag.CommandLine)
+	logging.Main()
+	run, err := o.Start(day, fname)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return run
+}
+
+// Start begins a run of the day over the named input file, writing its records to stdout
+func (o *Options) Start(day int, fname string) (*Run, error) {
+	return NewRun(os.Stdout, o.Format, day, fname)
+}
+
+// Run collects the records of the parts of one run
+type Run struct {
+	w       io.Writer
+	format  string
+	day     int
+	hash    string
+	records []Record
+	failed  bool
+	err     error // First error writing a record
+
+	now  func() time.Time // Clock timing the parts
+	exit func(code int)   // Ends the process once the records are written
+}
+
+// NewRun begins a run of the day over the named input file, writing its records to w in the format
+func NewRun(w io.Writer, format string, day int, fname string) (*Run, error) {
+	switch format {
+	case FormatTable, FormatJSON, FormatNDJSON:
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected %s, %s or %s", format, FormatTable, FormatJSON, FormatNDJSON)
+	}
+
+	// An unreadable input is left to the solution to report when it reads it
+	hash, _ := hashFile(fname)
+	return &Run{w: w, format: format, day: day, hash: hash, now: time.Now, exit: os.Exit}, nil
+}
+
+// hashFile returns the hex SHA-256 of the file's contents
+func hashFile(fname string) (string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Part times solving a part and records its answer, or its error
+func (r *Run) Part(part int, solve func() (any, error)) {
+	start := r.now()
+	answer, err := solve()
+	rec := Record{Day: r.day, Part: part, InputHash: r.hash, Duration: r.now().Sub(start)}
+	if err != nil {
+		rec.Error = err.Error()
+	} else {
+		rec.Answer = fmt.Sprint(answer)
+	}
+	r.add(rec)
+}
+
+// Fatal records an error that stops the run, writes the records and exits with status 1
+func (r *Run) Fatal(err error) {
+	slog.Error("run failed", "day", r.day, "err", err)
+	r.add(Record{Day: r.day, InputHash: r.hash, Error: err.Error()})
+	r.Finish()
+}
+
+// Finish writes the records if they are written at the end, and exits with status 1 if a part failed
+func (r *Run) Finish() {
+	if err := r.flush(); err != nil {
+		slog.Error("writing results", "err", err)
+		r.exit(1)
+		return
+	}
+	if r.failed {
+		r.exit(1)
+	}
+}
+
+// Failed checks if any part of the run has failed so far
+func (r *Run) Failed() bool {
+	return r.failed
+}
+
+// add keeps the record, streaming it straight away as NDJSON
+func (r *Run) add(rec Record) {
+	r.records = append(r.records, rec)
+	if rec.Error != "" {
+		r.failed = true
+	}
+	if r.format == FormatNDJSON && r.err == nil {
+		r.err = json.NewEncoder(r.w).Encode(rec)
+	}
+}
+
+// flush writes the records that wait for the end of the run
+func (r *Run) flush() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	switch r.format {
+	case FormatJSON:
+		enc := json.NewEncoder(r.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r.records)
+	case FormatTable:
+		return writeTable(r.w, r.records)
+	}
+	return nil
+}
+
+// writeTable writes the records as aligned columns, with an error column only if a part failed
+func writeTable(w io.Writer, records []Record) error {
+	var failed bool
+	for _, rec := range records {
+		failed = failed || rec.Error != ""
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "DAY\tPART\tANSWER\tDURATION\tINPUT"
+	if failed {
+		header += "\tERROR"
+	}
+	fmt.Fprintln(tw, header)
+	for _, rec := range records {
+		hash := rec.InputHash
+		if len(hash) > tableHashLen {
+			hash = hash[:tableHashLen]
+		}
+		row := fmt.Sprintf("%02d\t%d\t%s\t%s\t%s", rec.Day, rec.Part, rec.Answer, rec.Duration.Round(time.Microsecond), hash)
+		if failed {
+			row += "\t" + rec.Error
+		}
+		fmt.Fprintln(tw, row)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,106 @@
+package result
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// fakeClock returns times a fixed step apart, so every part takes the same time
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestGolden(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		run      func(r *Run)
+		wantExit int // -1 when the run doesn't exit
+	}{
+		{
+			name: "answers",
+			run: func(r *Run) {
+				r.Part(1, func() (any, error) { return 161, nil })
+				r.Part(2, func() (any, error) { return int64(65601038650482), nil })
+				r.Finish()
+			},
+			wantExit: -1,
+		},
+		{
+			name: "failed",
+			run: func(r *Run) {
+				r.Part(1, func() (any, error) { return 161, nil })
+				r.Part(2, func() (any, error) { return nil, errors.New("no answer") })
+				r.Finish()
+			},
+			wantExit: 1,
+		},
+		{
+			name: "fatal",
+			run: func(r *Run) {
+				r.Fatal(errors.New("error reading file [input.txt]: line 3: expected int"))
+			},
+			wantExit: 1,
+		},
+	}
+	for _, format := range []string{FormatTable, FormatJSON, FormatNDJSON} {
+		for _, sc := range scenarios {
+			t.Run(format+"/"+sc.name, func(t *testing.T) {
+				var out bytes.Buffer
+				r, err := NewRun(&out, format, 3, filepath.Join("testdata", "input.txt"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				exit := -1
+				r.now, r.exit = fakeClock(1500*time.Microsecond), func(code int) { exit = code }
+				sc.run(r)
+
+				if exit != sc.wantExit {
+					t.Errorf("exited with %d, want %d", exit, sc.wantExit)
+				}
+				golden := filepath.Join("testdata", format+"_"+sc.name+".golden")
+				if *update {
+					if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(out.Bytes(), want) {
+					t.Errorf("got\n%s\nwant\n%s", out.Bytes(), want)
+				}
+			})
+		}
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := NewRun(&bytes.Buffer{}, "xml", 3, "input.txt"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestMissingInputHash(t *testing.T) {
+	// An unreadable input is left to the solution, the records just have no hash
+	var out bytes.Buffer
+	r, err := NewRun(&out, FormatNDJSON, 3, filepath.Join("testdata", "missing.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Part(1, func() (any, error) { return 1, nil })
+	if !bytes.Contains(out.Bytes(), []byte(`"input_sha256":""`)) {
+		t.Errorf("got %s, want an empty input hash", out.Bytes())
+	}
+}